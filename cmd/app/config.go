@@ -1,16 +1,31 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/jasonuc/moota/internal/handlers"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/oidc"
+	"github.com/jasonuc/moota/internal/services"
+)
+
+const (
+	defaultAccessTokenSecret = "moo_goes_the_cow"
+	// the same as the output of HS256, shorter secrets are easier to brute force
+	minAccessTokenSecretLength = 32
 )
 
 type config struct {
 	env    string
 	server struct {
-		port int
+		port           int
+		trustedProxies []string
 	}
 	static struct {
 		path string
@@ -22,13 +37,79 @@ type config struct {
 		connMaxIdleTime time.Duration
 	}
 	auth struct {
-		accessTokenSecret  string
-		accessTokenTTL     time.Duration
-		refreshTokenTTL    time.Duration
-		issuer             string
-		cookieDomain       string
-		cookieSameSiteMode int
+		accessTokenSecret    string
+		signingKeyFile       string
+		verificationKeyFiles []string
+		accessTokenTTL       time.Duration
+		refreshTokenTTL      time.Duration
+		issuer               string
+		cookieDomain         string
+		cookieSameSiteMode   int
+
+		emailVerificationURL string
+		passwordResetURL     string
+
+		accountDeletionGracePeriod time.Duration
+
+		oidc struct {
+			providers       []oidcProviderConfig
+			callbackBaseURL string
+			loggedInURL     string
+			linkedURL       string
+			signupURL       string
+			setPasswordURL  string
+			errorURL        string
+		}
+
+		loginThrottle struct {
+			freeFailures          int
+			baseDelay             time.Duration
+			maxDelay              time.Duration
+			lockoutAfter          int
+			lockoutDuration       time.Duration
+			resetAfter            time.Duration
+			ipAddressFreeFailures int
+			ipAddressLockoutAfter int
+		}
+	}
+	mailer struct {
+		kind    string
+		logPath string
+		smtp    struct {
+			host     string
+			port     int
+			username string
+			password string
+			from     string
+		}
 	}
+	csrf struct {
+		trustedOrigins []string
+	}
+	moderation struct {
+		filterFile     string
+		reloadInterval time.Duration
+	}
+	worker struct {
+		decayEnabled    bool
+		decayInterval   time.Duration
+		decayBatchSize  int
+		decayStaleAfter time.Duration
+
+		accountDeletionInterval  time.Duration
+		accountDeletionBatchSize int
+	}
+	dev struct {
+		timeOffset time.Duration
+	}
+}
+
+type oidcProviderConfig struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	scopes       []string
 }
 
 func parseConfig() config {
@@ -37,6 +118,8 @@ func parseConfig() config {
 	cfg.env = getStringEnv("ENV", "development")
 
 	cfg.server.port = getIntEnv("PORT", 8080)
+	// the client's address is only taken from forwarding headers sent by these proxies
+	cfg.server.trustedProxies = getStringSliceEnv("TRUSTED_PROXIES", nil)
 
 	cfg.static.path = getStringEnv("STATIC_FILES_PATH", "dist")
 
@@ -45,16 +128,109 @@ func parseConfig() config {
 	cfg.db.maxIdleConns = getIntEnv("DB_MAX_IDLE_CONNS", 10)
 	cfg.db.connMaxIdleTime = getTimeDurationEnv("DB_CONN_MAX_IDLE_TIME", 1*time.Hour)
 
-	cfg.auth.accessTokenSecret = getStringEnv("AUTH_ACCESS_TOKEN_SECRET", "moo_goes_the_cow")
+	cfg.auth.accessTokenSecret = getStringEnv("AUTH_ACCESS_TOKEN_SECRET", defaultAccessTokenSecret)
+	// access tokens are signed with the RS256 or EdDSA private key in the signing key file when it is set, and with the secret otherwise.
+	// the verification key files are previous signing keys whose tokens are still accepted
+	cfg.auth.signingKeyFile = getStringEnv("AUTH_SIGNING_KEY_FILE", "")
+	cfg.auth.verificationKeyFiles = getStringSliceEnv("AUTH_VERIFICATION_KEY_FILES", nil)
 	cfg.auth.accessTokenTTL = getTimeDurationEnv("AUTH_ACCESS_TOKEN_TTL", 24*time.Hour)
 	cfg.auth.refreshTokenTTL = getTimeDurationEnv("AUTH_REFRESH_TOKEN_TTL", 7*24*time.Hour)
 	cfg.auth.issuer = getStringEnv("AUTH_ISSUER", "moota")
 	cfg.auth.cookieDomain = getStringEnv("AUTH_COOKIE_DOMAIN", "")
 	cfg.auth.cookieSameSiteMode = getIntEnv("AUTH_COOKIE_SAME_SITE_MODE", int(http.SameSiteStrictMode))
+	cfg.auth.emailVerificationURL = getStringEnv("AUTH_EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email")
+	cfg.auth.passwordResetURL = getStringEnv("AUTH_PASSWORD_RESET_URL", "http://localhost:8080/reset-password")
+	// deleted accounts are kept this long so logging back in can undo it, 0 deletes them straight away
+	cfg.auth.accountDeletionGracePeriod = getTimeDurationEnv("AUTH_ACCOUNT_DELETION_GRACE_PERIOD", 0)
+
+	// every provider named in AUTH_OIDC_PROVIDERS is configured by its own AUTH_OIDC_<NAME>_* env vars.
+	// providers redirect back to <callback base url>/api/auth/oidc/<name>/callback
+	for _, name := range getStringSliceEnv("AUTH_OIDC_PROVIDERS", nil) {
+		prefix := "AUTH_OIDC_" + strings.ToUpper(name) + "_"
+		cfg.auth.oidc.providers = append(cfg.auth.oidc.providers, oidcProviderConfig{
+			name:         strings.ToLower(name),
+			issuer:       getStringEnv(prefix+"ISSUER", ""),
+			clientID:     getStringEnv(prefix+"CLIENT_ID", ""),
+			clientSecret: getStringEnv(prefix+"CLIENT_SECRET", ""),
+			scopes:       getStringSliceEnv(prefix+"SCOPES", nil),
+		})
+	}
+	cfg.auth.oidc.callbackBaseURL = getStringEnv("AUTH_OIDC_CALLBACK_BASE_URL", "http://localhost:8080")
+	cfg.auth.oidc.loggedInURL = getStringEnv("AUTH_OIDC_LOGGED_IN_URL", "http://localhost:8080/home")
+	cfg.auth.oidc.linkedURL = getStringEnv("AUTH_OIDC_LINKED_URL", "http://localhost:8080/settings")
+	cfg.auth.oidc.signupURL = getStringEnv("AUTH_OIDC_SIGNUP_URL", "http://localhost:8080/oidc-signup")
+	// users without a password who log in with a provider again are sent here with a password reset token to set one
+	cfg.auth.oidc.setPasswordURL = getStringEnv("AUTH_OIDC_SET_PASSWORD_URL", cfg.auth.passwordResetURL)
+	cfg.auth.oidc.errorURL = getStringEnv("AUTH_OIDC_ERROR_URL", "http://localhost:8080/login")
+
+	// failed logins are throttled per username and, more leniently so shared networks aren't punished, per IP address
+	cfg.auth.loginThrottle.freeFailures = getIntEnv("AUTH_LOGIN_FREE_FAILURES", 3)
+	cfg.auth.loginThrottle.baseDelay = getTimeDurationEnv("AUTH_LOGIN_BASE_DELAY", 1*time.Second)
+	cfg.auth.loginThrottle.maxDelay = getTimeDurationEnv("AUTH_LOGIN_MAX_DELAY", 5*time.Minute)
+	cfg.auth.loginThrottle.lockoutAfter = getIntEnv("AUTH_LOGIN_LOCKOUT_AFTER", 10)
+	cfg.auth.loginThrottle.lockoutDuration = getTimeDurationEnv("AUTH_LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	cfg.auth.loginThrottle.resetAfter = getTimeDurationEnv("AUTH_LOGIN_RESET_AFTER", 24*time.Hour)
+	cfg.auth.loginThrottle.ipAddressFreeFailures = getIntEnv("AUTH_LOGIN_IP_FREE_FAILURES", 20)
+	cfg.auth.loginThrottle.ipAddressLockoutAfter = getIntEnv("AUTH_LOGIN_IP_LOCKOUT_AFTER", 100)
+
+	// "smtp" sends real emails, anything else writes them to MAILER_LOG_PATH or stdout when it is empty
+	cfg.mailer.kind = getStringEnv("MAILER", "log")
+	cfg.mailer.logPath = getStringEnv("MAILER_LOG_PATH", "")
+	cfg.mailer.smtp.host = getStringEnv("MAILER_SMTP_HOST", "localhost")
+	cfg.mailer.smtp.port = getIntEnv("MAILER_SMTP_PORT", 1025)
+	cfg.mailer.smtp.username = getStringEnv("MAILER_SMTP_USERNAME", "")
+	cfg.mailer.smtp.password = getStringEnv("MAILER_SMTP_PASSWORD", "")
+	cfg.mailer.smtp.from = getStringEnv("MAILER_SMTP_FROM", "Moota <no-reply@moota.local>")
+
+	cfg.csrf.trustedOrigins = getStringSliceEnv("CSRF_TRUSTED_ORIGINS", nil)
+
+	// the built in wordlist is used when no file is set, a file is reloaded whenever it changes
+	cfg.moderation.filterFile = getStringEnv("CONTENT_FILTER_FILE", "")
+	cfg.moderation.reloadInterval = getTimeDurationEnv("CONTENT_FILTER_RELOAD_INTERVAL", 30*time.Second)
+
+	cfg.worker.decayEnabled = getBoolEnv("WORKER_DECAY_ENABLED", true)
+	cfg.worker.decayInterval = getTimeDurationEnv("WORKER_DECAY_INTERVAL", 15*time.Minute)
+	cfg.worker.decayBatchSize = getIntEnv("WORKER_DECAY_BATCH_SIZE", 100)
+	// a refresh only moves a plant forward whole decay intervals, so it is pointless to refresh plants any sooner
+	cfg.worker.decayStaleAfter = getTimeDurationEnv("WORKER_DECAY_STALE_AFTER", models.HpDecayInterval)
+	// only runs when there is an account deletion grace period
+	cfg.worker.accountDeletionInterval = getTimeDurationEnv("WORKER_ACCOUNT_DELETION_INTERVAL", 1*time.Hour)
+	cfg.worker.accountDeletionBatchSize = getIntEnv("WORKER_ACCOUNT_DELETION_BATCH_SIZE", 100)
+
+	// only honoured in development
+	cfg.dev.timeOffset = getTimeDurationEnv("DEV_TIME_OFFSET", 0)
 
 	return cfg
 }
 
+// validate catches config that is only acceptable outside of production
+func (cfg config) validate() error {
+	if cfg.auth.signingKeyFile == "" {
+		// an empty HMAC key is accepted when signing, which would let anyone forge access tokens
+		if cfg.auth.accessTokenSecret == "" {
+			return errors.New("AUTH_ACCESS_TOKEN_SECRET can't be empty unless AUTH_SIGNING_KEY_FILE is set")
+		}
+		if cfg.env == "production" && cfg.auth.accessTokenSecret == defaultAccessTokenSecret {
+			return errors.New("refusing to start in production with the default access token secret, set AUTH_SIGNING_KEY_FILE or AUTH_ACCESS_TOKEN_SECRET")
+		}
+		if cfg.env == "production" && len(cfg.auth.accessTokenSecret) < minAccessTokenSecretLength {
+			return fmt.Errorf("AUTH_ACCESS_TOKEN_SECRET must be at least %d bytes in production", minAccessTokenSecretLength)
+		}
+	}
+	for _, provider := range cfg.auth.oidc.providers {
+		if provider.issuer == "" || provider.clientID == "" {
+			return fmt.Errorf("oidc provider %q needs an issuer and a client id", provider.name)
+		}
+	}
+	if cfg.worker.decayEnabled && (cfg.worker.decayInterval <= 0 || cfg.worker.decayBatchSize < 1) {
+		return errors.New("WORKER_DECAY_INTERVAL must be positive and WORKER_DECAY_BATCH_SIZE at least 1")
+	}
+	if cfg.auth.accountDeletionGracePeriod > 0 && (cfg.worker.accountDeletionInterval <= 0 || cfg.worker.accountDeletionBatchSize < 1) {
+		return errors.New("WORKER_ACCOUNT_DELETION_INTERVAL must be positive and WORKER_ACCOUNT_DELETION_BATCH_SIZE at least 1")
+	}
+	return nil
+}
+
 func getStringEnv(key, fallback string) string {
 	val, ok := os.LookupEnv(key)
 	if !ok {
@@ -63,6 +239,22 @@ func getStringEnv(key, fallback string) string {
 	return val
 }
 
+// getStringSliceEnv splits a comma separated env var
+func getStringSliceEnv(key string, fallback []string) []string {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	values := make([]string, 0)
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func getIntEnv(key string, fallback int) int {
 	val, ok := os.LookupEnv(key)
 	if !ok {
@@ -77,6 +269,20 @@ func getIntEnv(key string, fallback int) int {
 	return intVal
 }
 
+func getBoolEnv(key string, fallback bool) bool {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	boolVal, err := strconv.ParseBool(val)
+	if err != nil {
+		return fallback
+	}
+
+	return boolVal
+}
+
 func getTimeDurationEnv(key string, fallback time.Duration) time.Duration {
 	val, ok := os.LookupEnv(key)
 	if !ok {
@@ -90,3 +296,45 @@ func getTimeDurationEnv(key string, fallback time.Duration) time.Duration {
 
 	return duration
 }
+
+func loginThrottle(cfg config) services.LoginThrottle {
+	policy := models.LoginThrottlePolicy{
+		FreeFailures:    cfg.auth.loginThrottle.freeFailures,
+		BaseDelay:       cfg.auth.loginThrottle.baseDelay,
+		MaxDelay:        cfg.auth.loginThrottle.maxDelay,
+		LockoutAfter:    cfg.auth.loginThrottle.lockoutAfter,
+		LockoutDuration: cfg.auth.loginThrottle.lockoutDuration,
+		ResetAfter:      cfg.auth.loginThrottle.resetAfter,
+	}
+
+	ipAddressPolicy := policy
+	ipAddressPolicy.FreeFailures = cfg.auth.loginThrottle.ipAddressFreeFailures
+	ipAddressPolicy.LockoutAfter = cfg.auth.loginThrottle.ipAddressLockoutAfter
+
+	return services.LoginThrottle{Username: policy, IPAddress: ipAddressPolicy}
+}
+
+func oidcProviders(cfg config) []oidc.Provider {
+	providers := make([]oidc.Provider, 0, len(cfg.auth.oidc.providers))
+	for _, provider := range cfg.auth.oidc.providers {
+		providers = append(providers, oidc.NewProvider(oidc.Config{
+			Name:         provider.name,
+			Issuer:       provider.issuer,
+			ClientID:     provider.clientID,
+			ClientSecret: provider.clientSecret,
+			RedirectURL:  strings.TrimSuffix(cfg.auth.oidc.callbackBaseURL, "/") + "/api/auth/oidc/" + provider.name + "/callback",
+			Scopes:       provider.scopes,
+		}))
+	}
+	return providers
+}
+
+func oidcRedirects(cfg config) handlers.OIDCRedirects {
+	return handlers.OIDCRedirects{
+		LoggedInURL:    cfg.auth.oidc.loggedInURL,
+		LinkedURL:      cfg.auth.oidc.linkedURL,
+		SignupURL:      cfg.auth.oidc.signupURL,
+		SetPasswordURL: cfg.auth.oidc.setPasswordURL,
+		ErrorURL:       cfg.auth.oidc.errorURL,
+	}
+}
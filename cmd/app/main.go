@@ -5,8 +5,12 @@ import (
 	"os"
 
 	"github.com/jasonuc/moota/internal/handlers"
+	"github.com/jasonuc/moota/internal/mailer"
 	"github.com/jasonuc/moota/internal/middlewares"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
 	"github.com/jasonuc/moota/internal/services"
+	"github.com/jasonuc/moota/internal/signing"
 	"github.com/jasonuc/moota/internal/store"
 	"github.com/joho/godotenv"
 )
@@ -19,18 +23,33 @@ type application struct {
 
 	store *store.Store
 
+	contentFilter *moderation.WordlistFilter
+	mailQueue     *mailer.QueueMailer
+
 	plantService services.PlantService
 	soilService  services.SoilService
 	seedService  services.SeedService
 	authService  services.AuthService
+	oidcService  services.OIDCService
 	userService  services.UserService
 
-	authMiddleware middlewares.AuthMiddleware
+	achievementService services.AchievementService
+	adminService       services.AdminService
+
+	authMiddleware   middlewares.AuthMiddleware
+	csrfMiddleware   middlewares.CSRFMiddleware
+	realIPMiddleware middlewares.RealIPMiddleware
 
 	authHandler  *handlers.AuthHandler
+	oidcHandler  *handlers.OIDCHandler
 	seedHandler  *handlers.SeedHandler
 	plantHandler *handlers.PlantHandler
+	soilHandler  *handlers.SoilHandler
 	userHandler  *handlers.UserHandler
+
+	achievementHandler *handlers.AchievementHandler
+	adminHandler       *handlers.AdminHandler
+	devHandler         *handlers.DevHandler
 }
 
 func main() {
@@ -43,6 +62,18 @@ func main() {
 
 	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime)
 
+	if err := cfg.validate(); err != nil {
+		logger.Fatalf("error: %v\n", err)
+	}
+
+	keySet := signing.NewHMACKeySet([]byte(cfg.auth.accessTokenSecret))
+	if cfg.auth.signingKeyFile != "" {
+		keySet, err = signing.LoadKeySet(cfg.auth.signingKeyFile, cfg.auth.verificationKeyFiles)
+		if err != nil {
+			logger.Fatalf("error: %v\n", err)
+		}
+	}
+
 	db, err := openDB(cfg)
 	if err != nil {
 		logger.Panicf("error: %v\n", err)
@@ -52,36 +83,107 @@ func main() {
 
 	store := store.NewStore(db)
 
-	plantService := services.NewPlantService(store)
+	// in development the game clock can be shifted to simulate time passing.
+	// auth always uses the real time so that time travelling doesn't expire everyone's sessions
+	var gameClock models.Clock = models.SystemClock
+	var devClock *models.OffsetClock
+	if cfg.env == "development" {
+		devClock = models.NewOffsetClock(models.SystemClock, cfg.dev.timeOffset)
+		gameClock = devClock
+	}
+
+	contentFilter := moderation.NewDefaultFilter()
+	if cfg.moderation.filterFile != "" {
+		contentFilter, err = moderation.LoadWordlistFile(cfg.moderation.filterFile)
+		if err != nil {
+			logger.Fatalf("error: %v\n", err)
+		}
+	}
+
+	var appMailer mailer.Mailer
+	switch {
+	case cfg.mailer.kind == "smtp":
+		appMailer = mailer.NewSMTPMailer(cfg.mailer.smtp.host, cfg.mailer.smtp.port, cfg.mailer.smtp.username, cfg.mailer.smtp.password, cfg.mailer.smtp.from)
+	case cfg.mailer.logPath != "":
+		mailLog, err := os.OpenFile(cfg.mailer.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			logger.Panicf("error: %v\n", err)
+		}
+		//nolint:errcheck
+		defer mailLog.Close()
+		appMailer = mailer.NewLogMailer(mailLog)
+	default:
+		appMailer = mailer.NewLogMailer(os.Stdout)
+	}
+	mailQueue := mailer.NewQueueMailer(appMailer, 100, func(err error) {
+		logger.Printf("mailer: %v\n", err)
+	})
+
+	plantService := services.NewPlantService(store, gameClock, contentFilter)
 	soilService := services.NewSoilSerivce(store)
-	seedService := services.NewSeedService(store, soilService, plantService)
-	authService := services.NewAuthService(store, []byte(cfg.auth.accessTokenSecret), cfg.auth.refreshTokenTTL, cfg.auth.accessTokenTTL, cfg.auth.issuer)
-	userService := services.NewUserService(store)
+	seedService := services.NewSeedService(store, soilService, plantService, gameClock)
+	authService := services.NewAuthService(store, keySet, cfg.auth.refreshTokenTTL, cfg.auth.accessTokenTTL, cfg.auth.issuer, models.SystemClock, mailQueue, services.AuthEmailLinks{
+		VerifyEmailURL:   cfg.auth.emailVerificationURL,
+		ResetPasswordURL: cfg.auth.passwordResetURL,
+	}, loginThrottle(cfg), contentFilter, cfg.auth.accountDeletionGracePeriod)
+	oidcService := services.NewOIDCService(store, authService, oidcProviders(cfg), models.SystemClock, contentFilter)
+	userService := services.NewUserService(store, models.SystemClock)
+	achievementService := services.NewAchievementService(store)
+	adminService := services.NewAdminService(store, models.SystemClock, gameClock, contentFilter)
 
 	authMiddlware := middlewares.NewAuthMiddleware(authService)
+	csrfMiddleware := middlewares.NewCSRFMiddleware(cfg.csrf.trustedOrigins)
+	realIPMiddleware, err := middlewares.NewRealIPMiddleware(cfg.server.trustedProxies)
+	if err != nil {
+		logger.Fatalf("error: %v\n", err)
+	}
 
 	authHandler := handlers.NewAuthHandler(authService, cfg.auth.cookieDomain, cfg.auth.cookieSameSiteMode)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, authHandler, oidcRedirects(cfg))
 	seedHandler := handlers.NewSeedHandler(seedService)
 	plantHandler := handlers.NewPlantHandler(plantService)
+	soilHandler := handlers.NewSoilHandler(soilService)
 	userHandler := handlers.NewUserHandler(userService)
+	achievementHandler := handlers.NewAchievementHandler(achievementService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+
+	var devHandler *handlers.DevHandler
+	if devClock != nil {
+		devHandler = handlers.NewDevHandler(devClock)
+	}
 
 	app := application{
 		cfg:    cfg,
 		logger: logger,
 		store:  store,
 
+		contentFilter: contentFilter,
+		mailQueue:     mailQueue,
+
 		plantService: plantService,
 		soilService:  soilService,
 		seedService:  seedService,
 		authService:  authService,
+		oidcService:  oidcService,
 		userService:  userService,
 
-		authMiddleware: authMiddlware,
+		achievementService: achievementService,
+		adminService:       adminService,
+
+		authMiddleware:   authMiddlware,
+		csrfMiddleware:   csrfMiddleware,
+		realIPMiddleware: realIPMiddleware,
 
 		authHandler:  authHandler,
+		oidcHandler:  oidcHandler,
 		seedHandler:  seedHandler,
 		plantHandler: plantHandler,
+		soilHandler:  soilHandler,
 		userHandler:  userHandler,
+
+		achievementHandler: achievementHandler,
+		adminHandler:       adminHandler,
+		devHandler:         devHandler,
 	}
 
 	if err := app.serve(); err != nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/jasonuc/moota/internal/models"
 )
 
 func (app *application) routes() http.Handler {
@@ -16,9 +17,14 @@ func (app *application) routes() http.Handler {
 		r.Use(cors.AllowAll().Handler)
 	}
 
+	r.Use(app.realIPMiddleware.ResolveClientIP)
 	r.Use(middleware.Logger)
 
+	r.Get("/.well-known/jwks.json", app.authHandler.HandleGetJWKS)
+
 	r.Route("/api", func(r chi.Router) {
+		r.Use(app.csrfMiddleware.Protect)
+
 		r.Get("/health", app.healthCheckHandler)
 
 		r.Route("/auth", func(r chi.Router) {
@@ -26,14 +32,41 @@ func (app *application) routes() http.Handler {
 			r.Post("/login", app.authHandler.HandleLoginRequest)
 			r.Post("/refresh", app.authHandler.HandleAccessTokenRefresh)
 			r.Post("/logout", app.authHandler.HandleLogout)
+			r.Post("/verify-email", app.authHandler.HandleVerifyEmail)
+			r.Post("/password-reset", app.authHandler.HandleRequestPasswordReset)
+			r.Post("/password-reset/confirm", app.authHandler.HandleConfirmPasswordReset)
+
+			r.Route("/oidc", func(r chi.Router) {
+				r.Get("/providers", app.oidcHandler.HandleGetProviders)
+				r.Post("/signup", app.oidcHandler.HandleCompleteSignup)
+				r.Get("/{provider}/start", app.oidcHandler.HandleStartLogin)
+				r.Get("/{provider}/callback", app.oidcHandler.HandleCallback)
+			})
 
 			r.Route("/u/{userID}", func(r chi.Router) {
 				r.Use(app.authMiddleware.Authorise)
 				r.Use(app.authMiddleware.ValidateUserAccess)
 
+				r.Delete("/", app.authHandler.HandleDeleteAccount)
 				r.Patch("/change-email", app.authHandler.HandleChangeEmail)
+				r.Post("/verify-email", app.authHandler.HandleRequestEmailVerification)
 				r.Patch("/change-password", app.authHandler.HandleChangePassword)
 				r.Patch("/change-username", app.authHandler.HandleChangeUsername)
+				r.Post("/logout-everywhere", app.authHandler.HandleLogoutEverywhere)
+
+				r.Route("/sessions", func(r chi.Router) {
+					r.Get("/", app.authHandler.HandleGetSessions)
+					r.Post("/revoke-others", app.authHandler.HandleRevokeOtherSessions)
+					r.Delete("/{sessionID}", app.authHandler.HandleRevokeSession)
+				})
+
+				r.Route("/identities", func(r chi.Router) {
+					r.Get("/", app.oidcHandler.HandleGetIdentities)
+					r.Post("/{provider}", app.oidcHandler.HandleStartLink)
+					r.Delete("/{identityID}", app.oidcHandler.HandleUnlinkIdentity)
+				})
+
+				r.Post("/set-password/{provider}", app.oidcHandler.HandleStartSetPassword)
 			})
 		})
 
@@ -54,6 +87,9 @@ func (app *application) routes() http.Handler {
 					r.Group(func(r chi.Router) {
 						r.Use(app.authMiddleware.ValidateUserAccess)
 						r.Get("/", app.userHandler.HandleGetUser)
+						r.Get("/export", app.userHandler.HandleExportUserData)
+						r.Get("/achievements", app.achievementHandler.HandleGetUserAchievements)
+						r.Patch("/title", app.achievementHandler.HandleEquipTitle)
 					})
 				})
 			})
@@ -66,9 +102,14 @@ func (app *application) routes() http.Handler {
 					r.Get("/graveyard", app.plantHandler.HandleGetUserDeceasedPlants)
 				})
 
+				r.Get("/nearby", app.plantHandler.HandleGetNearbyPlants)
+
 				r.Route("/{plantID}", func(r chi.Router) {
 					r.Get("/", app.plantHandler.HandleGetPlant)
+					r.Get("/history", app.plantHandler.HandleGetPlantHistory)
 					r.Patch("/", app.plantHandler.HandleChangePlantNickname)
+					r.Patch("/care", app.plantHandler.HandleChangePlantCarePermission)
+					r.Patch("/location-sharing", app.plantHandler.HandleChangePlantLocationSharing)
 					r.Post("/action", app.plantHandler.HandleActionOnPlant)
 					r.Post("/kill", app.plantHandler.HandleKillPlant)
 				})
@@ -85,6 +126,37 @@ func (app *application) routes() http.Handler {
 
 				r.Post("/{seedID}", app.seedHandler.HandlePlantSeed)
 			})
+
+			r.Get("/soils", app.soilHandler.HandleGetSoilMap)
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(app.authMiddleware.RequireRole(models.RoleModerator))
+
+				r.Get("/users", app.adminHandler.HandleSearchUsers)
+				r.Patch("/users/{userID}/username", app.adminHandler.HandleRenameUser)
+				r.Post("/users/{userID}/revoke-sessions", app.adminHandler.HandleRevokeUserSessions)
+				r.Patch("/plants/{plantID}/nickname", app.adminHandler.HandleRenamePlant)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.authMiddleware.RequireRole(models.RoleAdmin))
+
+					r.Put("/users/{userID}/role", app.adminHandler.HandleSetUserRole)
+					r.Post("/users/{userID}/seeds", app.adminHandler.HandleGrantSeeds)
+					r.Post("/plants/{plantID}/revive", app.adminHandler.HandleRevivePlant)
+					r.Delete("/plants/{plantID}", app.adminHandler.HandleDeletePlant)
+					r.Get("/stats", app.adminHandler.HandleGetServerStats)
+					r.Get("/audit-log", app.adminHandler.HandleGetAuditLog)
+				})
+			})
+
+			if app.devHandler != nil {
+				r.Route("/dev", func(r chi.Router) {
+					r.Use(app.authMiddleware.RequireRole(models.RoleAdmin))
+
+					r.Get("/clock", app.devHandler.HandleGetClock)
+					r.Put("/clock", app.devHandler.HandleSetTimeOffset)
+				})
+			}
 		})
 	})
 
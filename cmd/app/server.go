@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -24,6 +25,45 @@ func (app *application) serve() error {
 
 	serverShutdownErr := make(chan error, 1)
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// emails are sent until every request has finished so none queued during shutdown are lost
+	mailCtx, stopMail := context.WithCancel(context.Background())
+	defer stopMail()
+
+	var mailSender sync.WaitGroup
+	mailSender.Add(1)
+	go func() {
+		defer mailSender.Done()
+		app.runMailQueue(mailCtx)
+	}()
+
+	var workers sync.WaitGroup
+	if app.cfg.worker.decayEnabled {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			app.runDecayWorker(workerCtx)
+		}()
+	}
+
+	if app.cfg.auth.accountDeletionGracePeriod > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			app.runAccountDeletionWorker(workerCtx)
+		}()
+	}
+
+	if app.cfg.moderation.filterFile != "" && app.cfg.moderation.reloadInterval > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			app.watchContentFilter(workerCtx)
+		}()
+	}
+
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
@@ -36,7 +76,15 @@ func (app *application) serve() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		if err := srv.Shutdown(ctx); err != nil {
+		stopWorkers()
+		workers.Wait()
+
+		err := srv.Shutdown(ctx)
+
+		stopMail()
+		mailSender.Wait()
+
+		if err != nil {
 			serverShutdownErr <- err
 			return
 		}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// runDecayWorker periodically refreshes plants nobody has looked at recently until ctx is cancelled.
+func (app *application) runDecayWorker(ctx context.Context) {
+	ticker := time.NewTicker(app.cfg.worker.decayInterval)
+	defer ticker.Stop()
+
+	app.logger.Printf("decay worker running every %s\n", app.cfg.worker.decayInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			app.logger.Print("decay worker stopped\n")
+			return
+		case <-ticker.C:
+			app.decayStalePlants(ctx)
+		}
+	}
+}
+
+func (app *application) decayStalePlants(ctx context.Context) {
+	total := 0
+	for ctx.Err() == nil {
+		refreshed, err := app.plantService.RefreshStalePlants(ctx, app.cfg.worker.decayStaleAfter, app.cfg.worker.decayBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				app.logger.Printf("decay worker: %v\n", err)
+			}
+			return
+		}
+
+		total += refreshed
+		if refreshed < app.cfg.worker.decayBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		app.logger.Printf("decay worker refreshed %d plants\n", total)
+	}
+}
+
+// runAccountDeletionWorker periodically deletes accounts whose deletion grace period has passed until ctx is cancelled.
+func (app *application) runAccountDeletionWorker(ctx context.Context) {
+	ticker := time.NewTicker(app.cfg.worker.accountDeletionInterval)
+	defer ticker.Stop()
+
+	app.logger.Printf("account deletion worker running every %s\n", app.cfg.worker.accountDeletionInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			app.logger.Print("account deletion worker stopped\n")
+			return
+		case <-ticker.C:
+			app.deleteScheduledAccounts(ctx)
+		}
+	}
+}
+
+func (app *application) deleteScheduledAccounts(ctx context.Context) {
+	total := 0
+	for ctx.Err() == nil {
+		deleted, err := app.authService.DeleteScheduledAccounts(ctx, app.cfg.worker.accountDeletionBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				app.logger.Printf("account deletion worker: %v\n", err)
+			}
+			return
+		}
+
+		total += deleted
+		if deleted < app.cfg.worker.accountDeletionBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		app.logger.Printf("account deletion worker deleted %d accounts\n", total)
+	}
+}
+
+// watchContentFilter reloads the content filter wordlist whenever the file changes until ctx is cancelled.
+func (app *application) watchContentFilter(ctx context.Context) {
+	app.logger.Printf("watching %s for content filter changes every %s\n", app.cfg.moderation.filterFile, app.cfg.moderation.reloadInterval)
+
+	app.contentFilter.Watch(ctx, app.cfg.moderation.reloadInterval, func() {
+		app.logger.Printf("content filter reloaded from %s\n", app.cfg.moderation.filterFile)
+	}, func(err error) {
+		app.logger.Printf("content filter: %v, keeping the previous wordlist\n", err)
+	})
+
+	app.logger.Print("content filter watcher stopped\n")
+}
+
+// runMailQueue sends queued emails until ctx is cancelled, then sends the ones still queued.
+func (app *application) runMailQueue(ctx context.Context) {
+	app.mailQueue.Run(ctx)
+	app.logger.Print("mail queue stopped\n")
+}
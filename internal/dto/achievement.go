@@ -0,0 +1,5 @@
+package dto
+
+type EquipTitleReq struct {
+	AchievementID string `json:"achievementID" validate:"max=50"`
+}
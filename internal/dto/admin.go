@@ -0,0 +1,33 @@
+package dto
+
+type AdminSetRoleReq struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+type AdminRenameUserReq struct {
+	NewUsername string `json:"newUsername" validate:"lowercase,min=3,max=20"`
+	Reason      string `json:"reason" validate:"max=500"`
+}
+
+type AdminRenamePlantReq struct {
+	NewNickname string `json:"newNickname" validate:"required,max=50"`
+	Reason      string `json:"reason" validate:"max=500"`
+}
+
+type AdminRevokeSessionsReq struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type AdminGrantSeedsReq struct {
+	Count  int    `json:"count" validate:"required,min=1,max=50"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type AdminRevivePlantReq struct {
+	Hp     float64 `json:"hp" validate:"omitempty,gt=0,lte=100"`
+	Reason string  `json:"reason" validate:"max=500"`
+}
+
+type AdminDeletePlantReq struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
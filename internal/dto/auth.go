@@ -10,6 +10,10 @@ type UserLoginReq struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
 type ChangeUsernameReq struct {
 	NewUsername string `json:"newUsername" validate:"required"`
 }
@@ -22,3 +26,25 @@ type ChangePasswordReq struct {
 	OldPassword string `json:"oldPassword" validate:"required"`
 	NewPassword string `json:"newPassword" validate:"required,min=8,max=72"`
 }
+
+type VerifyEmailReq struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type RequestPasswordResetReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ConfirmPasswordResetReq struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8,max=72"`
+}
+
+type OIDCSignupReq struct {
+	Token    string `json:"token" validate:"required"`
+	Username string `json:"username" validate:"lowercase,min=3,max=20"`
+}
+
+type DeleteAccountReq struct {
+	Password string `json:"password" validate:"required"`
+}
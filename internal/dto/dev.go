@@ -0,0 +1,5 @@
+package dto
+
+type SetTimeOffsetReq struct {
+	Offset string `json:"offset" validate:"required"` // a Go duration such as "168h" or "-2h30m"
+}
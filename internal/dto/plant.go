@@ -10,5 +10,14 @@ type ActionOnPlantReq struct {
 }
 
 type ChangePlantNicknameReq struct {
-	NewNickname string `json:"newNickname" validate:"required"`
+	NewNickname string `json:"newNickname" validate:"required,max=50"`
+}
+
+type ChangePlantCarePermissionReq struct {
+	Permission      string   `json:"permission" validate:"required,oneof=owner helpers everyone"`
+	HelperUsernames []string `json:"helperUsernames" validate:"omitempty,max=50,dive,required"`
+}
+
+type ChangePlantLocationSharingReq struct {
+	ShareExactLocation *bool `json:"shareExactLocation" validate:"required"`
 }
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/services"
+	"github.com/jasonuc/moota/internal/utils"
+)
+
+type AchievementHandler struct {
+	achievementService services.AchievementService
+	validator          *validator.Validate
+}
+
+func NewAchievementHandler(achievementService services.AchievementService) *AchievementHandler {
+	return &AchievementHandler{
+		achievementService: achievementService,
+		validator:          validator.New(),
+	}
+}
+
+func (h *AchievementHandler) HandleGetUserAchievements(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	achievements, err := h.achievementService.GetUserAchievements(r.Context(), userID)
+	if err != nil {
+		utils.ServerErrorResponse(w, err)
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"achievements": achievements, "catalog": models.AchievementCatalog}, nil)
+}
+
+func (h *AchievementHandler) HandleEquipTitle(w http.ResponseWriter, r *http.Request) {
+	var payload dto.EquipTitleReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	user, err := h.achievementService.EquipTitle(r.Context(), userID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, models.ErrAchievementNotFound):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, models.ErrAchievementNotEarned):
+			utils.NotPermittedResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"user": user}, nil)
+}
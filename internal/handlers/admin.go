@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jasonuc/moota/internal/contextkeys"
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/services"
+	"github.com/jasonuc/moota/internal/utils"
+)
+
+type AdminHandler struct {
+	adminService services.AdminService
+	validator    *validator.Validate
+}
+
+func NewAdminHandler(adminService services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		validator:    validator.New(),
+	}
+}
+
+func (h *AdminHandler) HandleSearchUsers(w http.ResponseWriter, r *http.Request) {
+	page, err := utils.ReadIntQueryParam(r, "page", 1)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	pageSize, err := utils.ReadIntQueryParam(r, "pageSize", services.AdminDefaultPageSize)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	users, hasMore, err := h.adminService.SearchUsers(r.Context(), r.URL.Query().Get("q"), page, pageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPagination):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"users": users, "page": page, "pageSize": pageSize, "hasMore": hasMore}, nil)
+}
+
+func (h *AdminHandler) HandleSetUserRole(w http.ResponseWriter, r *http.Request) {
+	actorID, userID, ok := h.readActorAndParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminSetRoleReq
+	if !h.readPayload(w, r, &payload, false) {
+		return
+	}
+
+	user, err := h.adminService.SetUserRole(r.Context(), actorID, userID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, models.ErrInvalidRole) || errors.Is(err, services.ErrCannotChangeOwnRole):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"user": user}, nil)
+}
+
+func (h *AdminHandler) HandleRenameUser(w http.ResponseWriter, r *http.Request) {
+	actorID, userID, ok := h.readActorAndParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminRenameUserReq
+	if !h.readPayload(w, r, &payload, false) {
+		return
+	}
+
+	user, err := h.adminService.RenameUser(r.Context(), actorID, userID, payload)
+	if err != nil {
+		var rejected *moderation.RejectedError
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrTargetHasHigherRole):
+			utils.NotPermittedResponse(w)
+		case errors.As(err, &rejected):
+			utils.RejectedFieldResponse(w, "NewUsername", rejected.Reason)
+		case errors.Is(err, services.ErrUsernameTooLong) || errors.Is(err, services.ErrUsernameMustContainOnlyLetters) || errors.Is(err, services.ErrUsernameTaken):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, services.ErrInvalidUsername):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"user": user}, nil)
+}
+
+func (h *AdminHandler) HandleRevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	actorID, userID, ok := h.readActorAndParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminRevokeSessionsReq
+	if !h.readPayload(w, r, &payload, true) {
+		return
+	}
+
+	if err := h.adminService.RevokeUserSessions(r.Context(), actorID, userID, payload); err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrTargetHasHigherRole):
+			utils.NotPermittedResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
+func (h *AdminHandler) HandleGrantSeeds(w http.ResponseWriter, r *http.Request) {
+	actorID, userID, ok := h.readActorAndParam(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminGrantSeedsReq
+	if !h.readPayload(w, r, &payload, false) {
+		return
+	}
+
+	seeds, err := h.adminService.GrantSeeds(r.Context(), actorID, userID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"seeds": seeds}, nil)
+}
+
+func (h *AdminHandler) HandleRenamePlant(w http.ResponseWriter, r *http.Request) {
+	actorID, plantID, ok := h.readActorAndParam(w, r, "plantID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminRenamePlantReq
+	if !h.readPayload(w, r, &payload, false) {
+		return
+	}
+
+	plant, err := h.adminService.RenamePlant(r.Context(), actorID, plantID, payload)
+	if err != nil {
+		var rejected *moderation.RejectedError
+		switch {
+		case errors.As(err, &rejected):
+			utils.RejectedFieldResponse(w, "NewNickname", rejected.Reason)
+		case errors.Is(err, models.ErrPlantNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrTargetHasHigherRole):
+			utils.NotPermittedResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"plant": plant}, nil)
+}
+
+func (h *AdminHandler) HandleRevivePlant(w http.ResponseWriter, r *http.Request) {
+	actorID, plantID, ok := h.readActorAndParam(w, r, "plantID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminRevivePlantReq
+	if !h.readPayload(w, r, &payload, true) {
+		return
+	}
+
+	plant, err := h.adminService.RevivePlant(r.Context(), actorID, plantID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPlantNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrPlantNotDead):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"plant": plant}, nil)
+}
+
+func (h *AdminHandler) HandleDeletePlant(w http.ResponseWriter, r *http.Request) {
+	actorID, plantID, ok := h.readActorAndParam(w, r, "plantID")
+	if !ok {
+		return
+	}
+
+	var payload dto.AdminDeletePlantReq
+	if !h.readPayload(w, r, &payload, true) {
+		return
+	}
+
+	if err := h.adminService.DeletePlant(r.Context(), actorID, plantID, payload); err != nil {
+		switch {
+		case errors.Is(err, models.ErrPlantNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
+func (h *AdminHandler) HandleGetServerStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.adminService.GetServerStats(r.Context())
+	if err != nil {
+		utils.ServerErrorResponse(w, err)
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"stats": stats}, nil)
+}
+
+func (h *AdminHandler) HandleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	page, err := utils.ReadIntQueryParam(r, "page", 1)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	pageSize, err := utils.ReadIntQueryParam(r, "pageSize", services.AdminDefaultPageSize)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	entries, hasMore, err := h.adminService.GetAuditLog(r.Context(), page, pageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPagination):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"entries": entries, "page": page, "pageSize": pageSize, "hasMore": hasMore}, nil)
+}
+
+// readActorAndParam reads the ID of the admin making the request, which is recorded in the audit log, and the given URL param.
+func (h *AdminHandler) readActorAndParam(w http.ResponseWriter, r *http.Request, key string) (string, string, bool) {
+	actorID, err := contextkeys.GetUserIDFromCtx(r.Context())
+	if err != nil {
+		utils.UnauthorizedResponse(w)
+		return "", "", false
+	}
+
+	param, err := utils.ReadStringReqParam(r, key)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return "", "", false
+	}
+
+	return actorID, param, true
+}
+
+// readPayload reads and validates the body, writing the error response if it can't. Optional bodies may be left out.
+func (h *AdminHandler) readPayload(w http.ResponseWriter, r *http.Request, payload any, optional bool) bool {
+	if err := utils.ReadJSON(w, r, payload); err != nil && !(optional && errors.Is(err, io.EOF)) {
+		utils.BadRequestResponse(w, err)
+		return false
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return false
+	}
+
+	return true
+}
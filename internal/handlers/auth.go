@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jasonuc/moota/internal/dto"
 	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
 	"github.com/jasonuc/moota/internal/services"
 	"github.com/jasonuc/moota/internal/utils"
 )
@@ -39,11 +42,14 @@ func (h *AuthHandler) HandleRegisterRequest(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	user, tokenPair, err := h.authService.Register(r.Context(), payload)
+	user, tokenPair, err := h.authService.Register(r.Context(), payload, clientInfo(r))
 	if err != nil {
+		var rejected *moderation.RejectedError
 		switch {
 		case errors.Is(err, services.ErrUsernameTooLong) || errors.Is(err, services.ErrUsernameMustContainOnlyLetters) || errors.Is(err, services.ErrUsernameTaken):
 			utils.BadRequestResponse(w, err)
+		case errors.As(err, &rejected):
+			utils.RejectedFieldResponse(w, "Username", rejected.Reason)
 		case errors.Is(err, services.ErrInvalidEmail):
 			utils.BadRequestResponse(w, err)
 		case errors.Is(err, services.ErrInvalidUsername):
@@ -57,8 +63,13 @@ func (h *AuthHandler) HandleRegisterRequest(w http.ResponseWriter, r *http.Reque
 	h.addCookie(w, "access_token", tokenPair.AccessToken, h.authService.GetAccessTokenTTL())
 	h.addCookie(w, "refresh_token", tokenPair.RefreshToken, h.authService.GetRefreshTokenTTL())
 
+	envelope := utils.Envelope{"user": user}
+	if wantsTokens(r) {
+		envelope["tokens"] = tokenPair
+	}
+
 	//nolint:errcheck
-	utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"user": user}, nil)
+	utils.WriteJSON(w, http.StatusCreated, envelope, nil)
 }
 
 func (h *AuthHandler) HandleLoginRequest(w http.ResponseWriter, r *http.Request) {
@@ -73,11 +84,14 @@ func (h *AuthHandler) HandleLoginRequest(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tokenPair, err := h.authService.Login(r.Context(), payload)
+	tokenPair, err := h.authService.Login(r.Context(), payload, clientInfo(r))
 	if err != nil {
+		var retryAfterErr *services.RetryAfterError
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			utils.InvalidCredentialsResponse(w)
+		case errors.As(err, &retryAfterErr):
+			utils.TooManyRequestsResponse(w, retryAfterErr.RetryAfter)
 		default:
 			utils.ServerErrorResponse(w, err)
 		}
@@ -87,26 +101,35 @@ func (h *AuthHandler) HandleLoginRequest(w http.ResponseWriter, r *http.Request)
 	h.addCookie(w, "access_token", tokenPair.AccessToken, h.authService.GetAccessTokenTTL())
 	h.addCookie(w, "refresh_token", tokenPair.RefreshToken, h.authService.GetRefreshTokenTTL())
 
+	var envelope utils.Envelope
+	if wantsTokens(r) {
+		envelope = utils.Envelope{"tokens": tokenPair}
+	}
+
 	//nolint:errcheck
-	utils.WriteJSON(w, http.StatusOK, nil, nil)
+	utils.WriteJSON(w, http.StatusOK, envelope, nil)
 }
 
+// HandleAccessTokenRefresh takes the refresh token from the refresh_token cookie browsers are given
+// or, for non-browser clients, from the body. Clients that send it in the body get the new token pair back in the body.
 func (h *AuthHandler) HandleAccessTokenRefresh(w http.ResponseWriter, r *http.Request) {
-	refreshToken, err := r.Cookie("refresh_token")
+	refreshToken, fromBody, err := h.readRefreshToken(w, r)
 	if err != nil {
-		switch {
-		case errors.Is(err, http.ErrNoCookie):
-			http.Error(w, "unauthorised", http.StatusUnauthorized)
-		default:
-			http.Error(w, "server error", http.StatusInternalServerError)
-		}
+		utils.BadRequestResponse(w, err)
 		return
 	}
 
-	tokenPair, err := h.authService.RefreshAccessToken(r.Context(), refreshToken.Value)
+	if refreshToken == "" {
+		utils.UnauthorizedResponse(w)
+		return
+	}
+
+	tokenPair, err := h.authService.RefreshAccessToken(r.Context(), refreshToken, clientInfo(r))
 	if err != nil {
 		switch {
-		case errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrTokenExpiredOrRevoked):
+		case errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrTokenExpiredOrRevoked) || errors.Is(err, services.ErrRefreshTokenReused):
+			h.deleteCookie(w, "access_token")
+			h.deleteCookie(w, "refresh_token")
 			utils.InvalidCredentialsResponse(w)
 		default:
 			utils.ServerErrorResponse(w, err)
@@ -117,8 +140,13 @@ func (h *AuthHandler) HandleAccessTokenRefresh(w http.ResponseWriter, r *http.Re
 	h.addCookie(w, "access_token", tokenPair.AccessToken, h.authService.GetAccessTokenTTL())
 	h.addCookie(w, "refresh_token", tokenPair.RefreshToken, h.authService.GetRefreshTokenTTL())
 
+	var envelope utils.Envelope
+	if fromBody || wantsTokens(r) {
+		envelope = utils.Envelope{"tokens": tokenPair}
+	}
+
 	//nolint:errcheck
-	utils.WriteJSON(w, http.StatusOK, nil, nil)
+	utils.WriteJSON(w, http.StatusOK, envelope, nil)
 }
 
 func (h *AuthHandler) HandleChangeUsername(w http.ResponseWriter, r *http.Request) {
@@ -141,9 +169,12 @@ func (h *AuthHandler) HandleChangeUsername(w http.ResponseWriter, r *http.Reques
 
 	user, err := h.authService.ChangeUserUsername(r.Context(), userID, payload)
 	if err != nil {
+		var rejected *moderation.RejectedError
 		switch {
 		case errors.Is(err, services.ErrUsernameTooLong) || errors.Is(err, services.ErrUsernameMustContainOnlyLetters) || errors.Is(err, services.ErrUsernameTaken):
 			utils.BadRequestResponse(w, err)
+		case errors.As(err, &rejected):
+			utils.RejectedFieldResponse(w, "NewUsername", rejected.Reason)
 		case errors.Is(err, services.ErrInvalidUsername):
 			utils.BadRequestResponse(w, err)
 		case errors.Is(err, services.ErrInvalidUsername):
@@ -176,21 +207,31 @@ func (h *AuthHandler) HandleChangePassword(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	user, err := h.authService.ChangeUserPassword(r.Context(), userID, payload)
+	user, tokenPair, err := h.authService.ChangeUserPassword(r.Context(), userID, payload, clientInfo(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrUserNotFound):
 			utils.NotFoundResponse(w)
 		case errors.Is(err, services.ErrInvalidCredentials):
 			utils.InvalidCredentialsResponse(w)
+		case errors.Is(err, services.ErrNoPasswordSet):
+			utils.BadRequestResponse(w, err)
 		default:
 			utils.ServerErrorResponse(w, err)
 		}
 		return
 	}
 
+	h.addCookie(w, "access_token", tokenPair.AccessToken, h.authService.GetAccessTokenTTL())
+	h.addCookie(w, "refresh_token", tokenPair.RefreshToken, h.authService.GetRefreshTokenTTL())
+
+	envelope := utils.Envelope{"user": user}
+	if wantsTokens(r) {
+		envelope["tokens"] = tokenPair
+	}
+
 	//nolint:errcheck
-	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"user": user}, nil)
+	utils.WriteJSON(w, http.StatusAccepted, envelope, nil)
 }
 
 func (h *AuthHandler) HandleChangeEmail(w http.ResponseWriter, r *http.Request) {
@@ -228,14 +269,343 @@ func (h *AuthHandler) HandleChangeEmail(w http.ResponseWriter, r *http.Request)
 	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"user": user}, nil)
 }
 
+func (h *AuthHandler) HandleRequestEmailVerification(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.authService.RequestEmailVerification(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrNoEmailToVerify) || errors.Is(err, services.ErrEmailAlreadyVerified):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusAccepted, nil, nil)
+}
+
+func (h *AuthHandler) HandleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var payload dto.VerifyEmailReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	user, err := h.authService.VerifyEmail(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidVerificationToken):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"user": user}, nil)
+}
+
+func (h *AuthHandler) HandleRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var payload dto.RequestPasswordResetReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), payload, clientInfo(r)); err != nil {
+		var retryAfterErr *services.RetryAfterError
+		switch {
+		case errors.As(err, &retryAfterErr):
+			utils.TooManyRequestsResponse(w, retryAfterErr.RetryAfter)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	// the same response is given whether or not the email belongs to an account
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"message": "If the email belongs to an account, a password reset link has been sent to it"}, nil)
+}
+
+func (h *AuthHandler) HandleConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var payload dto.ConfirmPasswordResetReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	if err := h.authService.ConfirmPasswordReset(r.Context(), payload); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPasswordResetToken):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.deleteCookie(w, "access_token")
+	h.deleteCookie(w, "refresh_token")
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
 func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	refreshToken, _, err := h.readRefreshToken(w, r)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	// the cookies are cleared even if there is no valid session to revoke
+	if refreshToken != "" {
+		if err := h.authService.Logout(r.Context(), refreshToken); err != nil && !errors.Is(err, services.ErrInvalidRefreshToken) {
+			utils.ServerErrorResponse(w, err)
+			return
+		}
+	}
+
+	h.deleteCookie(w, "access_token")
+	h.deleteCookie(w, "refresh_token")
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
+func (h *AuthHandler) HandleLogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.authService.LogoutEverywhere(r.Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.deleteCookie(w, "access_token")
+	h.deleteCookie(w, "refresh_token")
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
+// HandleDeleteAccount deletes the user, or schedules them to be deleted when there is a grace period. Either way they are logged out.
+func (h *AuthHandler) HandleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	var payload dto.DeleteAccountReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	deletionScheduledAt, err := h.authService.DeleteAccount(r.Context(), userID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			utils.InvalidCredentialsResponse(w)
+		case errors.Is(err, services.ErrNoPasswordSet):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
 	h.deleteCookie(w, "access_token")
 	h.deleteCookie(w, "refresh_token")
 
+	if deletionScheduledAt != nil {
+		//nolint:errcheck
+		utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"deletionScheduledAt": deletionScheduledAt}, nil)
+		return
+	}
+
 	//nolint:errcheck
 	utils.WriteJSON(w, http.StatusOK, nil, nil)
 }
 
+func (h *AuthHandler) HandleGetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	// bearer clients have no refresh token cookie, so they can send it in a header to have their session marked current
+	refreshToken := readCookieValue(r, "refresh_token")
+	if refreshToken == "" {
+		refreshToken = r.Header.Get("X-Refresh-Token")
+	}
+
+	sessions, err := h.authService.GetSessions(r.Context(), userID, refreshToken)
+	if err != nil {
+		utils.ServerErrorResponse(w, err)
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"sessions": sessions}, nil)
+}
+
+func (h *AuthHandler) HandleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	sessionID, err := utils.ReadStringReqParam(r, "sessionID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrSessionNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
+func (h *AuthHandler) HandleRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	refreshToken, fromBody, err := h.readRefreshToken(w, r)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	tokenPair, err := h.authService.RevokeOtherSessions(r.Context(), userID, refreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrTokenExpiredOrRevoked):
+			utils.InvalidCredentialsResponse(w)
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.addCookie(w, "access_token", tokenPair.AccessToken, h.authService.GetAccessTokenTTL())
+
+	var envelope utils.Envelope
+	if fromBody || wantsTokens(r) {
+		envelope = utils.Envelope{"tokens": tokenPair}
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, envelope, nil)
+}
+
+// readRefreshToken reads the refresh token from the refresh_token cookie or, failing that, from the body.
+// It reports whether the token came from the body and returns an empty token if there was none.
+func (h *AuthHandler) readRefreshToken(w http.ResponseWriter, r *http.Request) (string, bool, error) {
+	if refreshToken := readCookieValue(r, "refresh_token"); refreshToken != "" {
+		return refreshToken, false, nil
+	}
+
+	var payload dto.RefreshTokenReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	return payload.RefreshToken, payload.RefreshToken != "", nil
+}
+
+// wantsTokens reports whether the client asked for the token pair in the body, for clients that can't use cookies.
+func wantsTokens(r *http.Request) bool {
+	return utils.ReadBoolQueryParam(r, "includeTokens")
+}
+
+func clientInfo(r *http.Request) models.ClientInfo {
+	return models.NewClientInfo(r.UserAgent(), utils.ReadClientIP(r))
+}
+
+func readCookieValue(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// HandleGetJWKS serves the public keys access tokens are signed with so other services can verify them.
+func (h *AuthHandler) HandleGetJWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := json.Marshal(h.authService.JWKS())
+	if err != nil {
+		utils.ServerErrorResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	//nolint:errcheck
+	w.Write(jwks)
+}
+
 func (h *AuthHandler) addCookie(w http.ResponseWriter, name, value string, maxAge int) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     name,
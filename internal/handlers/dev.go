@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/utils"
+)
+
+// DevHandler exposes tools that are only available in development.
+type DevHandler struct {
+	clock     *models.OffsetClock
+	validator *validator.Validate
+}
+
+func NewDevHandler(clock *models.OffsetClock) *DevHandler {
+	return &DevHandler{
+		clock:     clock,
+		validator: validator.New(),
+	}
+}
+
+func (h *DevHandler) HandleGetClock(w http.ResponseWriter, r *http.Request) {
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"now": h.clock.Now(), "offset": h.clock.Offset().String()}, nil)
+}
+
+func (h *DevHandler) HandleSetTimeOffset(w http.ResponseWriter, r *http.Request) {
+	var payload dto.SetTimeOffsetReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	offset, err := time.ParseDuration(payload.Offset)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	h.clock.SetOffset(offset)
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"now": h.clock.Now(), "offset": h.clock.Offset().String()}, nil)
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/services"
+	"github.com/jasonuc/moota/internal/utils"
+)
+
+// oidcStateCookie ties the callback to the browser that started the login, so an attacker can't
+// send someone through a callback that logs them in as the attacker.
+const oidcStateCookie = "oidc_state"
+
+// OIDCRedirects are the frontend pages a user is sent to after coming back from a provider.
+// Errors are sent to ErrorURL with an error query param, signups to SignupURL with a token and suggested username,
+// and users setting a password to SetPasswordURL with a password reset token.
+type OIDCRedirects struct {
+	LoggedInURL    string
+	LinkedURL      string
+	SignupURL      string
+	SetPasswordURL string
+	ErrorURL       string
+}
+
+type OIDCHandler struct {
+	oidcService services.OIDCService
+	authHandler *AuthHandler
+	validator   *validator.Validate
+	redirects   OIDCRedirects
+}
+
+func NewOIDCHandler(oidcService services.OIDCService, authHandler *AuthHandler, redirects OIDCRedirects) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		authHandler: authHandler,
+		validator:   validator.New(),
+		redirects:   redirects,
+	}
+}
+
+func (h *OIDCHandler) HandleGetProviders(w http.ResponseWriter, r *http.Request) {
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"providers": h.oidcService.Providers()}, nil)
+}
+
+// HandleStartLogin sends the browser to the provider to log in.
+func (h *OIDCHandler) HandleStartLogin(w http.ResponseWriter, r *http.Request) {
+	provider, err := utils.ReadStringReqParam(r, "provider")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	authURL, state, err := h.oidcService.StartLogin(r.Context(), provider, nil)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownOIDCProvider):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.setStateCookie(w, state)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// HandleStartLink returns where to send a logged in user so they can link their account with the provider.
+// It is a POST returning the URL rather than a redirect so it is covered by the CSRF protection.
+func (h *OIDCHandler) HandleStartLink(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	provider, err := utils.ReadStringReqParam(r, "provider")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	authURL, state, err := h.oidcService.StartLogin(r.Context(), provider, &userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownOIDCProvider):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.setStateCookie(w, state)
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"authURL": authURL}, nil)
+}
+
+// HandleStartSetPassword returns where to send a logged in user without a password so they can log in with
+// the provider again before setting one. Like HandleStartLink it is a POST so it is covered by the CSRF protection.
+func (h *OIDCHandler) HandleStartSetPassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	provider, err := utils.ReadStringReqParam(r, "provider")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	authURL, state, err := h.oidcService.StartSetPassword(r.Context(), provider, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownOIDCProvider) || errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrPasswordAlreadySet) || errors.Is(err, services.ErrProviderNotLinked):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.setStateCookie(w, state)
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"authURL": authURL}, nil)
+}
+
+// HandleCallback is where the provider sends the browser back to. It always redirects to the frontend.
+func (h *OIDCHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	provider, err := utils.ReadStringReqParam(r, "provider")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	stateCookie := readCookieValue(r, oidcStateCookie)
+	h.authHandler.deleteCookie(w, oidcStateCookie)
+
+	if query.Get("error") != "" {
+		h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"login_cancelled"}})
+		return
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(stateCookie)) != 1 {
+		h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"login_failed"}})
+		return
+	}
+
+	result, err := h.oidcService.HandleCallback(r.Context(), provider, state, query.Get("code"), clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrIdentityAlreadyLinked):
+			h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"identity_already_linked"}})
+		case errors.Is(err, services.ErrProviderAlreadyLinked):
+			h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"provider_already_linked"}})
+		case errors.Is(err, services.ErrReauthenticationFailed):
+			h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"reauthentication_failed"}})
+		case errors.Is(err, services.ErrPasswordAlreadySet):
+			h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"password_already_set"}})
+		case errors.Is(err, services.ErrUnknownOIDCProvider) || errors.Is(err, services.ErrInvalidOIDCLogin):
+			h.redirect(w, r, h.redirects.ErrorURL, url.Values{"error": {"login_failed"}})
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	switch {
+	case result.TokenPair != nil:
+		h.authHandler.addCookie(w, "access_token", result.TokenPair.AccessToken, h.authHandler.authService.GetAccessTokenTTL())
+		h.authHandler.addCookie(w, "refresh_token", result.TokenPair.RefreshToken, h.authHandler.authService.GetRefreshTokenTTL())
+		h.redirect(w, r, h.redirects.LoggedInURL, nil)
+	case result.Linked:
+		h.redirect(w, r, h.redirects.LinkedURL, url.Values{"linked": {provider}})
+	case result.PasswordSetToken != "":
+		h.redirect(w, r, h.redirects.SetPasswordURL, url.Values{"token": {result.PasswordSetToken}})
+	default:
+		h.redirect(w, r, h.redirects.SignupURL, url.Values{
+			"token":    {result.Signup.Token},
+			"username": {result.Signup.SuggestedUsername},
+		})
+	}
+}
+
+// HandleCompleteSignup creates the user for a provider login once they have picked a username.
+func (h *OIDCHandler) HandleCompleteSignup(w http.ResponseWriter, r *http.Request) {
+	var payload dto.OIDCSignupReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	user, tokenPair, err := h.oidcService.CompleteSignup(r.Context(), payload, clientInfo(r))
+	if err != nil {
+		var rejected *moderation.RejectedError
+		switch {
+		case errors.Is(err, services.ErrUsernameTooLong) || errors.Is(err, services.ErrUsernameMustContainOnlyLetters) || errors.Is(err, services.ErrUsernameTaken):
+			utils.BadRequestResponse(w, err)
+		case errors.As(err, &rejected):
+			utils.RejectedFieldResponse(w, "Username", rejected.Reason)
+		case errors.Is(err, services.ErrInvalidUsername):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, services.ErrInvalidOIDCSignupToken) || errors.Is(err, services.ErrIdentityAlreadyLinked):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	h.authHandler.addCookie(w, "access_token", tokenPair.AccessToken, h.authHandler.authService.GetAccessTokenTTL())
+	h.authHandler.addCookie(w, "refresh_token", tokenPair.RefreshToken, h.authHandler.authService.GetRefreshTokenTTL())
+
+	envelope := utils.Envelope{"user": user}
+	if wantsTokens(r) {
+		envelope["tokens"] = tokenPair
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusCreated, envelope, nil)
+}
+
+func (h *OIDCHandler) HandleGetIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	identities, err := h.oidcService.GetIdentities(r.Context(), userID)
+	if err != nil {
+		utils.ServerErrorResponse(w, err)
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"identities": identities}, nil)
+}
+
+func (h *OIDCHandler) HandleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	identityID, err := utils.ReadStringReqParam(r, "identityID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.oidcService.UnlinkIdentity(r.Context(), userID, identityID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrIdentityNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrLastLoginMethod):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, nil, nil)
+}
+
+// setStateCookie is Lax rather than the usual SameSite mode because it has to come back on the provider's redirect
+func (h *OIDCHandler) setStateCookie(w http.ResponseWriter, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(models.OIDCLoginStateTTL.Seconds()),
+		Domain:   h.authHandler.cookieDomain,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *OIDCHandler) redirect(w http.ResponseWriter, r *http.Request, pageURL string, query url.Values) {
+	if len(query) > 0 {
+		pageURL += "?" + query.Encode()
+	}
+	http.Redirect(w, r, pageURL, http.StatusFound)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jasonuc/moota/internal/contextkeys"
 	"github.com/jasonuc/moota/internal/dto"
 	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
 	"github.com/jasonuc/moota/internal/services"
 	"github.com/jasonuc/moota/internal/store"
 	"github.com/jasonuc/moota/internal/utils"
@@ -82,14 +83,72 @@ func (h *PlantHandler) HandleGetPlant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if plant.OwnerID != userIDFromCtx {
-		plant.C = models.Coordinates{}
+	if !plant.LocationVisibleTo(userIDFromCtx) {
+		plant.HideLocation()
 	}
 
 	//nolint:errcheck
 	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"plant": plant}, nil)
 }
 
+func (h *PlantHandler) HandleGetNearbyPlants(w http.ResponseWriter, r *http.Request) {
+	userIDFromCtx, err := contextkeys.GetUserIDFromCtx(r.Context())
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	lat, err := utils.ReadFloatQueryParam(r, "lat")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	lon, err := utils.ReadFloatQueryParam(r, "lon")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	radiusM, err := utils.ReadIntQueryParam(r, "radius", services.NearbyPlantsDefaultRadiusM)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	page, err := utils.ReadIntQueryParam(r, "page", 1)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	pageSize, err := utils.ReadIntQueryParam(r, "pageSize", services.NearbyPlantsDefaultPageSize)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	plants, hasMore, err := h.plantService.GetNearbyPlants(r.Context(), models.Coordinates{Lat: lat, Lon: lon}, float64(radiusM), page, pageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCoordinates), errors.Is(err, services.ErrInvalidNearbyRadius), errors.Is(err, services.ErrInvalidPagination):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	for _, plant := range plants {
+		if !plant.LocationVisibleTo(userIDFromCtx) {
+			plant.HideLocation()
+		}
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"plants": plants, "page": page, "pageSize": pageSize, "hasMore": hasMore}, nil)
+}
+
 func (h *PlantHandler) HandleActionOnPlant(w http.ResponseWriter, r *http.Request) {
 	plantID, err := utils.ReadStringReqParam(r, "plantID")
 	if err != nil {
@@ -108,7 +167,13 @@ func (h *PlantHandler) HandleActionOnPlant(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	plant, err := h.plantService.ActionOnPlant(r.Context(), plantID, payload)
+	userIDFromCtx, err := contextkeys.GetUserIDFromCtx(r.Context())
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	plant, progress, err := h.plantService.ActionOnPlant(r.Context(), plantID, payload)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrUnauthorisedPlantAction):
@@ -121,14 +186,65 @@ func (h *PlantHandler) HandleActionOnPlant(w http.ResponseWriter, r *http.Reques
 			utils.BadRequestResponse(w, err)
 		case errors.Is(err, models.ErrPlantInCooldown):
 			utils.BadRequestResponse(w, err)
+		case errors.Is(err, models.ErrPlantFertiliseInCooldown):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, models.ErrPlantPruneInCooldown):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, models.ErrPlantTalkInCooldown):
+			utils.BadRequestResponse(w, err)
+		case errors.Is(err, services.ErrCommunityWateringCapReached):
+			utils.BadRequestResponse(w, err)
 		default:
 			utils.ServerErrorResponse(w, err)
 		}
 		return
 	}
 
+	// helpers watering someone else's plant don't get its exact location
+	if plant.OwnerID != userIDFromCtx {
+		plant.HideLocation()
+	}
+
 	//nolint:errcheck
-	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"plant": plant}, nil)
+	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"plant": plant, "progress": progress}, nil)
+}
+
+func (h *PlantHandler) HandleGetPlantHistory(w http.ResponseWriter, r *http.Request) {
+	plantID, err := utils.ReadStringReqParam(r, "plantID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	page, err := utils.ReadIntQueryParam(r, "page", 1)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	pageSize, err := utils.ReadIntQueryParam(r, "pageSize", services.PlantHistoryDefaultPageSize)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	events, hasMore, err := h.plantService.GetPlantHistory(r.Context(), plantID, page, pageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPlantNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrUnauthorisedPlantAction):
+			utils.NotPermittedResponse(w)
+		case errors.Is(err, services.ErrInvalidPagination):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"events": events, "page": page, "pageSize": pageSize, "hasMore": hasMore}, nil)
 }
 
 func (h *PlantHandler) HandleGetUserDeceasedPlants(w http.ResponseWriter, r *http.Request) {
@@ -148,6 +264,41 @@ func (h *PlantHandler) HandleGetUserDeceasedPlants(w http.ResponseWriter, r *htt
 	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"plants": deceasedPlants}, nil)
 }
 
+func (h *PlantHandler) HandleChangePlantLocationSharing(w http.ResponseWriter, r *http.Request) {
+	plantID, err := utils.ReadStringReqParam(r, "plantID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	var payload dto.ChangePlantLocationSharingReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	plant, err := h.plantService.ChangePlantLocationSharing(r.Context(), plantID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrPlantNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrUnauthorisedPlantAction):
+			utils.NotPermittedResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"plant": plant}, nil)
+}
+
 func (h *PlantHandler) HandleKillPlant(w http.ResponseWriter, r *http.Request) {
 	plantID, err := utils.ReadStringReqParam(r, "plantID")
 	if err != nil {
@@ -192,12 +343,52 @@ func (h *PlantHandler) HandleChangePlantNickname(w http.ResponseWriter, r *http.
 	}
 
 	plant, err := h.plantService.ChangePlantNickname(r.Context(), plantID, payload.NewNickname)
+	if err != nil {
+		var rejected *moderation.RejectedError
+		switch {
+		case errors.As(err, &rejected):
+			utils.RejectedFieldResponse(w, "NewNickname", rejected.Reason)
+		case errors.Is(err, models.ErrPlantNotFound):
+			utils.NotFoundResponse(w)
+		case errors.Is(err, services.ErrUnauthorisedPlantAction):
+			utils.NotPermittedResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusAccepted, utils.Envelope{"plant": plant}, nil)
+}
+
+func (h *PlantHandler) HandleChangePlantCarePermission(w http.ResponseWriter, r *http.Request) {
+	plantID, err := utils.ReadStringReqParam(r, "plantID")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	var payload dto.ChangePlantCarePermissionReq
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	if err := h.validator.Struct(payload); err != nil {
+		utils.FailedValidationResponse(w, err)
+		return
+	}
+
+	plant, err := h.plantService.ChangePlantCarePermission(r.Context(), plantID, payload)
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrPlantNotFound):
 			utils.NotFoundResponse(w)
 		case errors.Is(err, services.ErrUnauthorisedPlantAction):
 			utils.NotPermittedResponse(w)
+		case errors.Is(err, models.ErrInvalidCarePermission), errors.Is(err, services.ErrUnknownHelper):
+			utils.BadRequestResponse(w, err)
 		default:
 			utils.ServerErrorResponse(w, err)
 		}
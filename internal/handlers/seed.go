@@ -41,7 +41,7 @@ func (h *SeedHandler) HandlePlantSeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plant, err := h.seedService.PlantSeed(r.Context(), seedID, payload)
+	plant, progress, err := h.seedService.PlantSeed(r.Context(), seedID, payload)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrNotPossibleToCreatePlant):
@@ -57,7 +57,7 @@ func (h *SeedHandler) HandlePlantSeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//nolint:errcheck
-	utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"plant": plant}, nil)
+	utils.WriteJSON(w, http.StatusCreated, utils.Envelope{"plant": plant, "progress": progress}, nil)
 }
 
 func (h *SeedHandler) HandleGetUserSeeds(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/services"
+	"github.com/jasonuc/moota/internal/utils"
+)
+
+type SoilHandler struct {
+	soilService services.SoilService
+}
+
+func NewSoilHandler(soilService services.SoilService) *SoilHandler {
+	return &SoilHandler{
+		soilService: soilService,
+	}
+}
+
+func (h *SoilHandler) HandleGetSoilMap(w http.ResponseWriter, r *http.Request) {
+	lat, err := utils.ReadFloatQueryParam(r, "lat")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	lon, err := utils.ReadFloatQueryParam(r, "lon")
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	radiusM, err := utils.ReadIntQueryParam(r, "radius", services.SoilMapDefaultRadiusM)
+	if err != nil {
+		utils.BadRequestResponse(w, err)
+		return
+	}
+
+	soils, err := h.soilService.GetSoilMap(r.Context(), models.Coordinates{Lat: lat, Lon: lon}, float64(radiusM))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCoordinates), errors.Is(err, services.ErrInvalidSoilMapRadius):
+			utils.BadRequestResponse(w, err)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	//nolint:errcheck
+	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"soils": soils}, nil)
+}
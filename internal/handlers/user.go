@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"archive/zip"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -111,3 +115,90 @@ func (h *UserHandler) HandleWhoAmI(w http.ResponseWriter, r *http.Request) {
 	//nolint:errcheck
 	utils.WriteJSON(w, http.StatusOK, utils.Envelope{"user": user}, nil)
 }
+
+// HandleExportUserData sends the user everything stored about them as a JSON file or, with ?format=zip, a ZIP of JSON files.
+func (h *UserHandler) HandleExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.ReadStringReqParam(r, "userID")
+	if err != nil || userID == "" {
+		utils.BadRequestResponse(w, fmt.Errorf("missing required param userID"))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		utils.BadRequestResponse(w, fmt.Errorf("format must be json or zip"))
+		return
+	}
+
+	export, err := h.userService.ExportUserData(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			utils.NotFoundResponse(w)
+		default:
+			utils.ServerErrorResponse(w, err)
+		}
+		return
+	}
+
+	filename := fmt.Sprintf("moota-%s-%s", export.User.Username, export.ExportedAt.UTC().Format("20060102T150405Z"))
+
+	// the headers are sent before the body is written, so an error after this point can only be logged
+	if format == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+		w.WriteHeader(http.StatusOK)
+		if err := writeExportZip(w, export); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(export); err != nil {
+		log.Println(err)
+	}
+}
+
+// writeExportZip streams the export as a ZIP with a JSON file for each kind of data.
+func writeExportZip(w io.Writer, export *models.UserDataExport) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"user.json", export.User},
+		{"seeds.json", export.Seeds},
+		{"plants.json", export.Plants},
+		{"seed_requests.json", export.SeedRequests},
+		{"sessions.json", export.Sessions},
+		{"identities.json", export.Identities},
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(fw)
+		encoder.SetIndent("", "\t")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,14 @@
+package mailer
+
+import "context"
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(context.Context, Message) error
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+)
+
+// QueueMailer hands emails to Run to send in the background, so a request that sends an email takes no longer
+// than one that doesn't and can't give away whether it did.
+type QueueMailer struct {
+	next    Mailer
+	queue   chan Message
+	onError func(error)
+}
+
+func NewQueueMailer(next Mailer, size int, onError func(error)) *QueueMailer {
+	return &QueueMailer{
+		next:    next,
+		queue:   make(chan Message, size),
+		onError: onError,
+	}
+}
+
+// Send queues the email, only waiting when the queue is full.
+func (m *QueueMailer) Send(ctx context.Context, msg Message) error {
+	select {
+	case m.queue <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run sends queued emails until ctx is done, then sends whatever is still queued.
+func (m *QueueMailer) Run(ctx context.Context) {
+	for {
+		select {
+		case msg := <-m.queue:
+			m.send(msg)
+		case <-ctx.Done():
+			for {
+				select {
+				case msg := <-m.queue:
+					m.send(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (m *QueueMailer) send(msg Message) {
+	if err := m.next.Send(context.Background(), msg); err != nil {
+		m.onError(err)
+	}
+}
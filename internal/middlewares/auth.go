@@ -1,9 +1,12 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/jasonuc/moota/internal/contextkeys"
+	"github.com/jasonuc/moota/internal/models"
 	"github.com/jasonuc/moota/internal/services"
 	"github.com/jasonuc/moota/internal/utils"
 )
@@ -11,6 +14,7 @@ import (
 type AuthMiddleware interface {
 	Authorise(http.Handler) http.Handler
 	ValidateUserAccess(http.Handler) http.Handler
+	RequireRole(models.Role) func(http.Handler) http.Handler
 }
 
 type authMiddleware struct {
@@ -25,13 +29,13 @@ func NewAuthMiddleware(authService services.AuthService) AuthMiddleware {
 
 func (m *authMiddleware) Authorise(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		accessToken, err := r.Cookie("access_token")
-		if err != nil || accessToken.Value == "" {
+		accessToken := readAccessToken(r)
+		if accessToken == "" {
 			utils.UnauthorizedResponse(w)
 			return
 		}
 
-		userID, err := m.authService.VerifyAccessToken(r.Context(), accessToken.Value)
+		userID, err := m.authService.VerifyAccessToken(r.Context(), accessToken)
 		if err != nil {
 			utils.UnauthorizedResponse(w)
 			return
@@ -42,6 +46,20 @@ func (m *authMiddleware) Authorise(next http.Handler) http.Handler {
 	})
 }
 
+// readAccessToken reads the access token from the Authorization header for non-browser clients,
+// falling back to the access_token cookie browsers are given.
+func readAccessToken(r *http.Request) string {
+	if scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " "); found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	cookie, err := r.Cookie("access_token")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
 func (m *authMiddleware) ValidateUserAccess(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userIDFromCtx, err := contextkeys.GetUserIDFromCtx(r.Context())
@@ -63,3 +81,35 @@ func (m *authMiddleware) ValidateUserAccess(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireRole only lets through users with at least the given role. It has to come after Authorise.
+// The role is looked up on every request so taking it away takes effect straight away.
+func (m *authMiddleware) RequireRole(role models.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userIDFromCtx, err := contextkeys.GetUserIDFromCtx(r.Context())
+			if err != nil {
+				utils.UnauthorizedResponse(w)
+				return
+			}
+
+			userRole, err := m.authService.GetUserRole(r.Context(), userIDFromCtx)
+			if err != nil {
+				switch {
+				case errors.Is(err, models.ErrUserNotFound):
+					utils.UnauthorizedResponse(w)
+				default:
+					utils.ServerErrorResponse(w, err)
+				}
+				return
+			}
+
+			if !userRole.AtLeast(role) {
+				utils.NotPermittedResponse(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	handler := NewCSRFMiddleware([]string{"http://localhost:5173/"}).Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{name: "safe methods are not checked", method: http.MethodGet, headers: map[string]string{"Origin": "https://evil.example"}, status: http.StatusOK},
+		{name: "same origin", method: http.MethodPost, headers: map[string]string{"Origin": "https://moota.localhost"}, status: http.StatusOK},
+		{name: "trusted origin", method: http.MethodPost, headers: map[string]string{"Origin": "http://localhost:5173"}, status: http.StatusOK},
+		{name: "cross-site origin", method: http.MethodPost, headers: map[string]string{"Origin": "https://evil.example"}, status: http.StatusForbidden},
+		{name: "trusted host with another scheme", method: http.MethodPatch, headers: map[string]string{"Origin": "https://localhost:5173"}, status: http.StatusForbidden},
+		{name: "opaque origin", method: http.MethodPost, headers: map[string]string{"Origin": "null"}, status: http.StatusForbidden},
+		{name: "same origin fetch metadata", method: http.MethodDelete, headers: map[string]string{"Sec-Fetch-Site": "same-origin"}, status: http.StatusOK},
+		{name: "cross-site fetch metadata", method: http.MethodDelete, headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, status: http.StatusForbidden},
+		{name: "same origin referer", method: http.MethodPost, headers: map[string]string{"Referer": "https://moota.localhost/plants"}, status: http.StatusOK},
+		{name: "cross-site referer", method: http.MethodPost, headers: map[string]string{"Referer": "https://evil.example/page"}, status: http.StatusForbidden},
+		{name: "bearer token", method: http.MethodPost, headers: map[string]string{"Origin": "https://evil.example", "Authorization": "Bearer token"}, status: http.StatusOK},
+		{name: "non-browser client", method: http.MethodPost, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "https://moota.localhost/api/plants/1/action", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type RealIPMiddleware interface {
+	ResolveClientIP(http.Handler) http.Handler
+}
+
+// realIPMiddleware replaces the request's RemoteAddr with the client's address from X-Forwarded-For or X-Real-IP,
+// but only when the request came from one of the trusted proxies. Anyone can set those headers, so trusting them
+// from everyone would let a client pick a new IP address for every request and dodge the per IP rate limits.
+//
+// X-Forwarded-For is read from the right, skipping the trusted proxies, so addresses the client added itself are ignored.
+type realIPMiddleware struct {
+	trustedProxies []netip.Prefix
+}
+
+// NewRealIPMiddleware takes the addresses, like "10.0.0.1" or "10.0.0.0/8", of the proxies in front of the app.
+// With none, the forwarding headers are never trusted.
+func NewRealIPMiddleware(trustedProxies []string) (RealIPMiddleware, error) {
+	prefixes := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return &realIPMiddleware{trustedProxies: prefixes}, nil
+}
+
+func (m *realIPMiddleware) ResolveClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clientIP, ok := m.clientIP(r); ok {
+			r.RemoteAddr = clientIP
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *realIPMiddleware) clientIP(r *http.Request) (string, bool) {
+	if !m.trusted(hostOf(r.RemoteAddr)) {
+		return "", false
+	}
+
+	if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+
+		// every hop was a trusted proxy, so the leftmost is as close to the client as can be known
+		clientIP := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				break
+			}
+
+			clientIP = hop
+			if !m.trusted(hop) {
+				break
+			}
+		}
+		return clientIP, clientIP != ""
+	}
+
+	realIP := strings.TrimSpace(r.Header.Get("X-Real-IP"))
+	if _, err := netip.ParseAddr(realIP); err != nil {
+		return "", false
+	}
+	return realIP, true
+}
+
+func (m *realIPMiddleware) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range m.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOf(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	m, err := NewRealIPMiddleware([]string{"10.0.0.1", "172.16.0.0/12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clientIP string
+	handler := m.ResolveClientIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = r.RemoteAddr
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		clientIP   string
+	}{
+		{name: "untrusted peer", remoteAddr: "203.0.113.9:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"}, clientIP: "203.0.113.9:1234"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.1"}, clientIP: "198.51.100.1"},
+		{name: "trusted proxy range", remoteAddr: "172.20.0.5:1234", headers: map[string]string{"X-Real-IP": "198.51.100.2"}, clientIP: "198.51.100.2"},
+		{name: "addresses the client added are ignored", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1, 172.16.0.1"}, clientIP: "198.51.100.1"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "172.16.0.2, 172.16.0.1"}, clientIP: "172.16.0.2"},
+		{name: "malformed header", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "not-an-ip"}, clientIP: "10.0.0.1:1234"},
+		{name: "no headers", remoteAddr: "10.0.0.1:1234", clientIP: "10.0.0.1:1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.clientIP, clientIP)
+		})
+	}
+
+	t.Run("invalid proxies are refused", func(t *testing.T) {
+		_, err := NewRealIPMiddleware([]string{"not-a-proxy"})
+		assert.Error(t, err)
+	})
+}
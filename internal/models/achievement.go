@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAchievementNotFound  = errors.New("achievement not found")
+	ErrAchievementNotEarned = errors.New("achievement not earned")
+)
+
+type AchievementID string
+
+const (
+	AchievementFirstPlant         AchievementID = "first_plant"
+	AchievementTenPlants          AchievementID = "ten_plants"
+	AchievementTenWaterings       AchievementID = "ten_waterings"
+	AchievementHundredWaterings   AchievementID = "hundred_waterings"
+	AchievementPlantSurvived30d   AchievementID = "plant_survived_30_days"
+	AchievementPlantSurvived100d  AchievementID = "plant_survived_100_days"
+	AchievementAllSoilTypes       AchievementID = "all_soil_types"
+	AchievementPlantReachedLevel5 AchievementID = "plant_reached_level_5"
+	AchievementGoodNeighbour      AchievementID = "good_neighbour"
+)
+
+// AchievementStats are the figures about a player's history that achievements are earned against.
+type AchievementStats struct {
+	PlantsPlanted      int64
+	Waterings          int64
+	CommunityWaterings int64 // waterings of other players' plants
+	DistinctSoilTypes  int64
+	HighestPlantLevel  int64
+	LongestPlantLife   time.Duration
+}
+
+type AchievementMeta struct {
+	ID          AchievementID `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Title       string        `json:"title"` // can be equipped as the player's title once earned
+	earned      func(AchievementStats) bool
+}
+
+type Achievement struct {
+	AchievementMeta
+	UserID   string    `json:"-"`
+	EarnedAt time.Time `json:"earnedAt"`
+}
+
+var AchievementCatalog = []AchievementMeta{
+	{
+		ID:          AchievementFirstPlant,
+		Name:        "First Sprout",
+		Description: "Plant your first seed",
+		Title:       "Sprout",
+		earned:      func(s AchievementStats) bool { return s.PlantsPlanted >= 1 },
+	},
+	{
+		ID:          AchievementTenPlants,
+		Name:        "Gardener",
+		Description: "Plant 10 seeds",
+		Title:       "Gardener",
+		earned:      func(s AchievementStats) bool { return s.PlantsPlanted >= 10 },
+	},
+	{
+		ID:          AchievementTenWaterings,
+		Name:        "Watering Can",
+		Description: "Water your plants 10 times",
+		Title:       "Waterer",
+		earned:      func(s AchievementStats) bool { return s.Waterings >= 10 },
+	},
+	{
+		ID:          AchievementHundredWaterings,
+		Name:        "Rainmaker",
+		Description: "Water your plants 100 times",
+		Title:       "Rainmaker",
+		earned:      func(s AchievementStats) bool { return s.Waterings >= 100 },
+	},
+	{
+		ID:          AchievementPlantSurvived30d,
+		Name:        "Survivor",
+		Description: "Keep a plant alive for 30 days",
+		Title:       "Caretaker",
+		earned:      func(s AchievementStats) bool { return s.LongestPlantLife >= 30*24*time.Hour },
+	},
+	{
+		ID:          AchievementPlantSurvived100d,
+		Name:        "Evergreen",
+		Description: "Keep a plant alive for 100 days",
+		Title:       "Evergreen",
+		earned:      func(s AchievementStats) bool { return s.LongestPlantLife >= 100*24*time.Hour },
+	},
+	{
+		ID:          AchievementAllSoilTypes,
+		Name:        "Soil Explorer",
+		Description: "Grow a plant in every type of soil",
+		Title:       "Soil Explorer",
+		earned:      func(s AchievementStats) bool { return s.DistinctSoilTypes >= 4 },
+	},
+	{
+		ID:          AchievementPlantReachedLevel5,
+		Name:        "Green Thumb",
+		Description: "Raise a plant to level 5",
+		Title:       "Green Thumb",
+		earned:      func(s AchievementStats) bool { return s.HighestPlantLevel >= 5 },
+	},
+	{
+		ID:          AchievementGoodNeighbour,
+		Name:        "Good Neighbour",
+		Description: "Water other players' plants 10 times",
+		Title:       "Good Neighbour",
+		earned:      func(s AchievementStats) bool { return s.CommunityWaterings >= 10 },
+	},
+}
+
+func GetAchievementMeta(id AchievementID) (AchievementMeta, error) {
+	for _, meta := range AchievementCatalog {
+		if meta.ID == id {
+			return meta, nil
+		}
+	}
+	return AchievementMeta{}, ErrAchievementNotFound
+}
+
+// EarnedAchievements returns the achievements from the catalog that stats qualify for.
+func EarnedAchievements(stats AchievementStats) []AchievementMeta {
+	earned := make([]AchievementMeta, 0)
+	for _, meta := range AchievementCatalog {
+		if meta.earned(stats) {
+			earned = append(earned, meta)
+		}
+	}
+	return earned
+}
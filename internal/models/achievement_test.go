@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarnedAchievements(t *testing.T) {
+	earnedIDs := func(stats AchievementStats) []AchievementID {
+		ids := make([]AchievementID, 0)
+		for _, meta := range EarnedAchievements(stats) {
+			ids = append(ids, meta.ID)
+		}
+		return ids
+	}
+
+	t.Run("new player has no achievements", func(t *testing.T) {
+		assert.Empty(t, earnedIDs(AchievementStats{}))
+	})
+
+	t.Run("first plant", func(t *testing.T) {
+		assert.Equal(t, []AchievementID{AchievementFirstPlant}, earnedIDs(AchievementStats{PlantsPlanted: 1}))
+	})
+
+	t.Run("waterings", func(t *testing.T) {
+		assert.NotContains(t, earnedIDs(AchievementStats{Waterings: 9}), AchievementTenWaterings)
+		assert.Contains(t, earnedIDs(AchievementStats{Waterings: 10}), AchievementTenWaterings)
+	})
+
+	t.Run("plant survived 30 days", func(t *testing.T) {
+		ids := earnedIDs(AchievementStats{PlantsPlanted: 1, LongestPlantLife: 30 * 24 * time.Hour})
+
+		assert.Contains(t, ids, AchievementPlantSurvived30d)
+		assert.NotContains(t, ids, AchievementPlantSurvived100d)
+	})
+
+	t.Run("all soil types", func(t *testing.T) {
+		assert.NotContains(t, earnedIDs(AchievementStats{DistinctSoilTypes: 3}), AchievementAllSoilTypes)
+		assert.Contains(t, earnedIDs(AchievementStats{DistinctSoilTypes: 4}), AchievementAllSoilTypes)
+	})
+}
+
+func TestGetAchievementMeta(t *testing.T) {
+	t.Run("achievement in catalog", func(t *testing.T) {
+		meta, err := GetAchievementMeta(AchievementFirstPlant)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, meta.Title)
+	})
+
+	t.Run("achievement not in catalog", func(t *testing.T) {
+		_, err := GetAchievementMeta("not_an_achievement")
+
+		assert.ErrorIs(t, err, ErrAchievementNotFound)
+	})
+}
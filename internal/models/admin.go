@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionSetRole        AuditAction = "set_role"
+	AuditActionRenameUser     AuditAction = "rename_user"
+	AuditActionRenamePlant    AuditAction = "rename_plant"
+	AuditActionRevokeSessions AuditAction = "revoke_sessions"
+	AuditActionGrantSeeds     AuditAction = "grant_seeds"
+	AuditActionRevivePlant    AuditAction = "revive_plant"
+	AuditActionDeletePlant    AuditAction = "delete_plant"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetUser  AuditTargetType = "user"
+	AuditTargetPlant AuditTargetType = "plant"
+)
+
+// AuditLogEntry records an action taken through the admin API. ActorID is nil once the admin who took it has been deleted.
+type AuditLogEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    *string         `json:"actorID"`
+	Action     AuditAction     `json:"action"`
+	TargetType AuditTargetType `json:"targetType"`
+	TargetID   string          `json:"targetID"`
+	Details    json.RawMessage `json:"details"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// ServerStats is an overview of the game for admins.
+type ServerStats struct {
+	Users          int64 `json:"users"`
+	NewUsers       int64 `json:"newUsers"` // in the last day
+	ActiveSessions int64 `json:"activeSessions"`
+	AlivePlants    int64 `json:"alivePlants"`
+	DeceasedPlants int64 `json:"deceasedPlants"`
+	UnplantedSeeds int64 `json:"unplantedSeeds"`
+}
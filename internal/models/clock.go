@@ -0,0 +1,44 @@
+package models
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock tells the current time. It lets time dependent behaviour be tested without relying on time.Now.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+var SystemClock Clock = systemClock{}
+
+// OffsetClock runs a settable offset ahead of another clock.
+// It is only meant to be used in development to simulate time passing.
+type OffsetClock struct {
+	base   Clock
+	offset atomic.Int64
+}
+
+func NewOffsetClock(base Clock, offset time.Duration) *OffsetClock {
+	c := &OffsetClock{base: base}
+	c.SetOffset(offset)
+	return c
+}
+
+func (c *OffsetClock) Now() time.Time {
+	return c.base.Now().Add(c.Offset())
+}
+
+func (c *OffsetClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+func (c *OffsetClock) SetOffset(offset time.Duration) {
+	c.offset.Store(int64(offset))
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetClock(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("runs ahead of its base clock by the offset", func(t *testing.T) {
+		clock := NewOffsetClock(fixedClock(now), 24*time.Hour)
+
+		assert.Equal(t, now.Add(24*time.Hour), clock.Now())
+	})
+
+	t.Run("offset can be changed", func(t *testing.T) {
+		clock := NewOffsetClock(fixedClock(now), 0)
+
+		clock.SetOffset(7 * 24 * time.Hour)
+
+		assert.Equal(t, 7*24*time.Hour, clock.Offset())
+		assert.Equal(t, now.Add(7*24*time.Hour), clock.Now())
+	})
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "errors"
+
+// CarePermission is who, besides its owner, may water a plant.
+type CarePermission string
+
+const (
+	CarePermissionOwner    CarePermission = "owner"    // only the owner
+	CarePermissionHelpers  CarePermission = "helpers"  // the players the owner has listed as the plant's helpers
+	CarePermissionEveryone CarePermission = "everyone" // any player
+)
+
+// CommunityWateringDailyCap is how many other players' plants a player may water in 24 hours.
+const CommunityWateringDailyCap = 5
+
+var ErrInvalidCarePermission = errors.New("invalid care permission")
+
+func ParseCarePermission(permission string) (CarePermission, error) {
+	switch CarePermission(permission) {
+	case CarePermissionOwner, CarePermissionHelpers, CarePermissionEveryone:
+		return CarePermission(permission), nil
+	default:
+		return "", ErrInvalidCarePermission
+	}
+}
+
+// AllowsCommunityWateringBy reports whether the owner lets userID water the plant.
+// listedHelper is whether userID is one of the plant's helpers.
+func (p *Plant) AllowsCommunityWateringBy(userID string, listedHelper bool) bool {
+	if userID == p.OwnerID {
+		return true
+	}
+
+	switch p.CarePermission {
+	case CarePermissionEveryone:
+		return true
+	case CarePermissionHelpers:
+		return listedHelper
+	default:
+		return false
+	}
+}
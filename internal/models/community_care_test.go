@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunityCare(t *testing.T) {
+	ownerID := "owner"
+	helperID := "helper"
+
+	tests := []struct {
+		name         string
+		permission   CarePermission
+		userID       string
+		listedHelper bool
+		allowed      bool
+	}{
+		{name: "owner can always water", permission: CarePermissionOwner, userID: ownerID, allowed: true},
+		{name: "others cannot water an owner only plant", permission: CarePermissionOwner, userID: helperID, listedHelper: true, allowed: false},
+		{name: "listed helper can water", permission: CarePermissionHelpers, userID: helperID, listedHelper: true, allowed: true},
+		{name: "unlisted player cannot water a helpers plant", permission: CarePermissionHelpers, userID: helperID, allowed: false},
+		{name: "anyone can water an everyone plant", permission: CarePermissionEveryone, userID: helperID, allowed: true},
+		{name: "plants without a permission are owner only", permission: "", userID: helperID, listedHelper: true, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{OwnerID: ownerID, CarePermission: tt.permission}
+
+			assert.Equal(t, tt.allowed, plant.AllowsCommunityWateringBy(tt.userID, tt.listedHelper))
+		})
+	}
+
+	t.Run("unknown care permissions are rejected", func(t *testing.T) {
+		_, err := ParseCarePermission("friends_of_friends")
+		assert.ErrorIs(t, err, ErrInvalidCarePermission)
+
+		permission, err := ParseCarePermission("everyone")
+		assert.NoError(t, err)
+		assert.Equal(t, CarePermissionEveryone, permission)
+	})
+}
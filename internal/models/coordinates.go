@@ -60,3 +60,12 @@ func CoordinatesFromPostGIS(pointText string) (Coordinates, error) {
 
 	return Coordinates{Lat: lat, Lon: lon}, nil
 }
+
+// OffsetM returns the point eastM metres east and northM metres north of p.
+// It treats the earth as flat around p so is only meant for the short distances within a soil.
+func (p Coordinates) OffsetM(eastM, northM float64) Coordinates {
+	return Coordinates{
+		Lat: p.Lat + (northM/earthRadiusM)*(180/math.Pi),
+		Lon: p.Lon + (eastM/(earthRadiusM*math.Cos(p.latRad())))*(180/math.Pi),
+	}
+}
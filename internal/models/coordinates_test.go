@@ -50,3 +50,11 @@ func TestCoordinatesFromPostGIS(t *testing.T) {
 		assert.EqualValues(t, got, Coordinates{})
 	})
 }
+
+func TestOffsetM(t *testing.T) {
+	p := Coordinates{Lat: 51.5007, Lon: -0.1246}
+
+	assert.InDelta(t, 20, p.DistanceM(p.OffsetM(20, 0)), 0.01)
+	assert.InDelta(t, 20, p.DistanceM(p.OffsetM(0, -20)), 0.01)
+	assert.InDelta(t, 50, p.DistanceM(p.OffsetM(30, 40)), 0.01)
+}
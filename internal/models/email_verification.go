@@ -0,0 +1,29 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const EmailVerificationTokenTTL = 24 * time.Hour
+
+var (
+	ErrEmailVerificationTokenNotFound = errors.New("email verification token not found")
+)
+
+// EmailVerificationToken proves the user owns Email. It can only be used once and only while
+// Email is still the user's address.
+type EmailVerificationToken struct {
+	ID        string
+	UserID    string
+	Email     string
+	Hash      []byte
+	Plain     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func (t *EmailVerificationToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// how long a user has to log in with the provider after starting
+	OIDCLoginStateTTL = 10 * time.Minute
+	// how long a user has to pick a username after logging in with a provider for the first time
+	OIDCSignupTTL = 30 * time.Minute
+	// how far a provider's clock may be behind ours when checking a user logged in again after being sent to it
+	OIDCAuthTimeLeeway = time.Minute
+
+	maxSuggestedUsernameLength = 20
+)
+
+var (
+	ErrIdentityNotFound       = errors.New("identity not found")
+	ErrOIDCLoginStateNotFound = errors.New("oidc login state not found")
+	ErrOIDCSignupNotFound     = errors.New("oidc signup not found")
+)
+
+// Identity is a user's account with an OIDC provider, which they can log in with.
+type Identity struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"userID"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"-"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
+
+// OIDCLoginState is what is needed to finish a login that was sent to a provider.
+// LinkUserID is set when a logged in user is linking the provider to their account rather than logging in, and
+// SetPasswordUserID when a user without a password is logging in with the provider again so they can set one.
+type OIDCLoginState struct {
+	State             string
+	Provider          string
+	Nonce             string
+	CodeVerifier      string
+	LinkUserID        *string
+	SetPasswordUserID *string
+	CreatedAt         time.Time
+	ExpiresAt         time.Time
+}
+
+// OIDCSignup is a provider login that didn't match any user. A new user is made for it once they pick a username.
+type OIDCSignup struct {
+	ID                string
+	Hash              []byte
+	Plain             string
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	SuggestedUsername string
+	CreatedAt         time.Time
+	ExpiresAt         time.Time
+}
+
+// SuggestUsername makes a username out of what the provider knows the user as. Usernames can only have letters,
+// so the result may be too short or already taken; it is only a starting point for the user to pick from.
+func SuggestUsername(preferredUsername, email string) string {
+	source := preferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(source) {
+		if unicode.IsLetter(r) && r < unicode.MaxASCII {
+			b.WriteRune(r)
+		}
+		if b.Len() == maxSuggestedUsernameLength {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestUsername(t *testing.T) {
+	t.Run("uses the preferred username", func(t *testing.T) {
+		assert.Equal(t, "janedoe", SuggestUsername("Jane.Doe", "someone@example.com"))
+	})
+
+	t.Run("falls back to the email", func(t *testing.T) {
+		assert.Equal(t, "jdoe", SuggestUsername("", "j.doe42@example.com"))
+	})
+
+	t.Run("drops characters usernames can't have", func(t *testing.T) {
+		assert.Equal(t, "bob", SuggestUsername("bob_123!", ""))
+	})
+
+	t.Run("is cut to length", func(t *testing.T) {
+		assert.Len(t, SuggestUsername("abcdefghijklmnopqrstuvwxyz", ""), 20)
+	})
+}
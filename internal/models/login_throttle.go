@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// LoginThrottlePolicy decides how long logins are blocked for after repeated failures.
+// The first FreeFailures are not delayed, after that every failure doubles the delay from BaseDelay up to MaxDelay,
+// and once there have been LockoutAfter failures logins are locked out for LockoutDuration.
+// Failures are forgotten once there hasn't been one for ResetAfter.
+type LoginThrottlePolicy struct {
+	FreeFailures    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutAfter    int
+	LockoutDuration time.Duration
+	ResetAfter      time.Duration
+}
+
+// LoginFailures is the count of recent failed logins for a username or IP address.
+type LoginFailures struct {
+	Key          string
+	Failures     int
+	LastFailedAt time.Time
+}
+
+func UsernameLoginFailuresKey(username string) string {
+	return "username:" + strings.ToLower(username)
+}
+
+func IPAddressLoginFailuresKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+// BlockedUntil returns when logins are allowed again after the failures. It is the zero time if they are not blocked.
+func (p LoginThrottlePolicy) BlockedUntil(f *LoginFailures, now time.Time) time.Time {
+	if f == nil || f.Failures <= p.FreeFailures || now.Sub(f.LastFailedAt) >= p.ResetAfter {
+		return time.Time{}
+	}
+
+	if p.LockoutAfter > 0 && f.Failures >= p.LockoutAfter {
+		return f.LastFailedAt.Add(p.LockoutDuration)
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeFailures + 1; i < f.Failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return f.LastFailedAt.Add(min(delay, p.MaxDelay))
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottlePolicy(t *testing.T) {
+	policy := LoginThrottlePolicy{
+		FreeFailures:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		LockoutAfter:    10,
+		LockoutDuration: 15 * time.Minute,
+		ResetAfter:      24 * time.Hour,
+	}
+	lastFailedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := lastFailedAt.Add(time.Millisecond)
+
+	t.Run("no failures are not blocked", func(t *testing.T) {
+		assert.True(t, policy.BlockedUntil(nil, now).IsZero())
+	})
+
+	t.Run("free failures are not blocked", func(t *testing.T) {
+		failures := &LoginFailures{Failures: 3, LastFailedAt: lastFailedAt}
+		assert.True(t, policy.BlockedUntil(failures, now).IsZero())
+	})
+
+	t.Run("delay doubles with every failure up to the max", func(t *testing.T) {
+		expected := map[int]time.Duration{
+			4: time.Second,
+			5: 2 * time.Second,
+			6: 4 * time.Second,
+			7: 8 * time.Second,
+			8: 10 * time.Second,
+			9: 10 * time.Second,
+		}
+		for failures, delay := range expected {
+			got := policy.BlockedUntil(&LoginFailures{Failures: failures, LastFailedAt: lastFailedAt}, now)
+			assert.Equal(t, lastFailedAt.Add(delay), got, "failures: %d", failures)
+		}
+	})
+
+	t.Run("locks out after enough failures", func(t *testing.T) {
+		failures := &LoginFailures{Failures: 10, LastFailedAt: lastFailedAt}
+		assert.Equal(t, lastFailedAt.Add(15*time.Minute), policy.BlockedUntil(failures, now))
+	})
+
+	t.Run("old failures are forgotten", func(t *testing.T) {
+		failures := &LoginFailures{Failures: 10, LastFailedAt: lastFailedAt}
+		assert.True(t, policy.BlockedUntil(failures, lastFailedAt.Add(24*time.Hour)).IsZero())
+	})
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	PasswordResetTokenTTL = time.Hour
+
+	// reset requests are limited over a sliding window, per account and per IP
+	PasswordResetRateLimitWindow         = time.Hour
+	PasswordResetMaxRequestsPerAccount   = 3
+	PasswordResetMaxRequestsPerIPAddress = 10
+)
+
+var (
+	ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+)
+
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	Hash      []byte
+	Plain     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+func (t *PasswordResetToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// PasswordResetRequest is a request to reset a password. UserID is nil when the request did not match an account.
+type PasswordResetRequest struct {
+	UserID      *string
+	IPAddress   string
+	RequestedAt time.Time
+}
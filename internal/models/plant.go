@@ -10,50 +10,42 @@ import (
 
 const (
 	PlantInteractionRadius = 15 // TODO: This value is still experimental
-	wateringPlantXpGain    = 30
-	wateringPlantHpGain    = 5
 
-	hpDecayInterval = 4 * time.Hour
+	HpDecayInterval = 4 * time.Hour
 
-	wateringGracePeriod = 4 * time.Hour
-	wateringCooldown    = 3 * time.Hour
+	MinRefreshInterval = 5 * time.Minute
 
-	minRefreshInterval = 5 * time.Minute
+	// PlantLocationFuzzM is how far from where it really is a plant can be shown to other players
+	PlantLocationFuzzM = 100
 )
 
 var (
-	ErrPlantInCooldown     = errors.New("plant is in cooldown mode")
 	ErrPlantNotFullyInSoil = errors.New("plant not fully inside soil")
 	ErrPlantNotFound       = errors.New("plant not found")
 )
 
-type PlantAction int
-
-const (
-	PlantActionWater PlantAction = iota + 1
-)
-
-func ValidPlantAction(action int) bool {
-	m := map[PlantAction]bool{
-		PlantActionWater: true,
-	}
-	return m[PlantAction(action)]
-}
-
 type Plant struct {
-	ID                string     `json:"id"`
-	Nickname          string     `json:"nickname"`
-	Hp                float64    `json:"hp"`
-	Dead              bool       `json:"dead"`
-	OwnerID           string     `json:"ownerID"`
-	Soil              *Soil      `json:"soil,omitempty"`
-	Tempers           *Tempers   `json:"tempers,omitempty"`
-	TimePlanted       time.Time  `json:"timePlanted"`
-	TimeOfDeath       *time.Time `json:"timeOfDeath"`
-	LastWateredAt     time.Time  `json:"lastWateredAt"`
-	LastActionAt      time.Time  `json:"lastActionAt"`
-	LastRefreshedAt   *time.Time `json:"lastRefreshedAt,omitempty"`
-	GracePeriodEndsAt *time.Time `json:"gracePeriodEndsAt,omitempty"`
+	ID                 string                   `json:"id"`
+	Nickname           string                   `json:"nickname"`
+	Hp                 float64                  `json:"hp"`
+	Dead               bool                     `json:"dead"`
+	OwnerID            string                   `json:"ownerID"`
+	Soil               *Soil                    `json:"soil,omitempty"`
+	Tempers            *Tempers                 `json:"tempers,omitempty"`
+	TimePlanted        time.Time                `json:"timePlanted"`
+	TimeOfDeath        *time.Time               `json:"timeOfDeath"`
+	LastWateredAt      time.Time                `json:"lastWateredAt"`
+	LastActionAt       time.Time                `json:"lastActionAt"`
+	LastFertilisedAt   *time.Time               `json:"lastFertilisedAt"`
+	LastPrunedAt       *time.Time               `json:"lastPrunedAt"`
+	LastTalkedToAt     *time.Time               `json:"lastTalkedToAt"`
+	CarePermission     CarePermission           `json:"carePermission"`
+	ShareExactLocation bool                     `json:"shareExactLocation"`
+	LastRefreshedAt    *time.Time               `json:"lastRefreshedAt,omitempty"`
+	GracePeriodEndsAt  *time.Time               `json:"gracePeriodEndsAt,omitempty"`
+	SoilEffects        *SoilEffects             `json:"soilEffects,omitempty"`
+	Availability       *PlantActionAvailability `json:"availability,omitempty"`
+	events             []*PlantEvent
 	SeedMeta
 	LevelMeta
 	CircleMeta
@@ -64,7 +56,29 @@ type PlantWithDistanceMFromUser struct {
 	DistanceM float64 `json:"distanceM"`
 }
 
-func NewPlant(seed *Seed, soil *Soil, centre Coordinates) (*Plant, error) {
+// NearbyPlant is another player's plant as it is shown to the players around it. Location is only exact
+// when the owner shares it, DistanceM is measured from Location so it can't be used to work out the real one.
+type NearbyPlant struct {
+	Plant
+	Location      Coordinates `json:"location"`
+	ExactLocation bool        `json:"exactLocation"`
+	DistanceM     float64     `json:"distanceM"`
+}
+
+// LocationVisibleTo reports whether the player can be shown exactly where the plant is.
+func (p *Plant) LocationVisibleTo(userID string) bool {
+	return p.OwnerID == userID || p.ShareExactLocation
+}
+
+// HideLocation removes where the plant is along with its soil, since the soil's centre is listed publicly
+// and the plant is never more than the soil's radius away from it.
+func (p *Plant) HideLocation() {
+	p.C = Coordinates{}
+	p.Soil = nil
+}
+
+// NewPlant plants the seed in the soil at time t.
+func NewPlant(seed *Seed, soil *Soil, centre Coordinates, t time.Time) (*Plant, error) {
 	if seed.Planted {
 		return nil, ErrSeedAlreadyPlanted
 	}
@@ -90,122 +104,173 @@ func NewPlant(seed *Seed, soil *Soil, centre Coordinates) (*Plant, error) {
 	}
 
 	return &Plant{
-		Nickname:  nickname,
-		Hp:        seed.Hp + healthOffset,
-		Soil:      soil,
-		OwnerID:   seed.OwnerID,
-		Dead:      false,
-		LevelMeta: NewLeveLMeta(1, xpBonus),
-		Tempers:   NewTempers(),
-		SeedMeta:  seed.SeedMeta,
+		Nickname:       nickname,
+		Hp:             seed.Hp + healthOffset,
+		Soil:           soil,
+		OwnerID:        seed.OwnerID,
+		Dead:           false,
+		LevelMeta:      NewLeveLMeta(1, xpBonus),
+		Tempers:        NewTempers(),
+		CarePermission: CarePermissionOwner,
+		SeedMeta:       seed.SeedMeta,
 		CircleMeta: CircleMeta{
 			C: centre,
 			R: PlantInteractionRadius,
 		},
+		TimePlanted:       t,
+		LastWateredAt:     t,
+		LastActionAt:      t,
 		LastRefreshedAt:   nil,
 		GracePeriodEndsAt: nil,
 	}, nil
 }
 
-func (p *Plant) Action(action PlantAction, t time.Time) (bool, error) {
-	p.applyTimeBasedChanges(t)
+// Action applies an action to the plant at time t after catching up on the time based changes since it was last refreshed.
+// neighbours are the living plants within MaliceReachM of the plant.
+func (p *Plant) Action(action PlantAction, t time.Time, neighbours ...*Plant) (bool, error) {
+	p.loadSoilEffects()
+	defer p.loadAvailability(t)
+
+	p.applyTimeBasedChanges(t, neighbours)
 
 	if !p.Alive() {
 		return p.Alive(), nil
 	}
 
+	hpBefore, levelBefore := p.Hp, p.Level
+
+	var xpGained int64
+	var err error
 	switch action {
 	case PlantActionWater:
-		if p.LastWateredAt.IsZero() || t.Sub(p.LastWateredAt) >= wateringCooldown {
-			p.addXp(wateringPlantXpGain)
-			p.changeHp(wateringPlantHpGain)
-			p.LastWateredAt = t
+		xpGained, err = p.water(t)
+	case PlantActionFertilise:
+		xpGained, err = p.fertilise(t)
+	case PlantActionPrune:
+		xpGained, err = p.prune(t)
+	case PlantActionTalk:
+		xpGained, err = p.talk(t)
+	default:
+		err = ErrUnknownPlantAction
+	}
 
-			gracePeriodEnd := t.Add(wateringGracePeriod)
-			p.GracePeriodEndsAt = &gracePeriodEnd
-		} else {
-			return p.Alive(), ErrPlantInCooldown
-		}
+	if err != nil {
+		return p.Alive(), err
 	}
 
 	p.LastActionAt = t
+	p.recordEvent(&PlantEvent{Type: PlantEventAction, Action: action, HpDelta: p.Hp - hpBefore, XpDelta: xpGained, OccurredAt: t})
+	if p.Level > levelBefore {
+		p.recordEvent(&PlantEvent{Type: PlantEventLevelUp, Action: action, Level: p.Level, OccurredAt: t})
+	}
+
 	return p.Alive(), nil
 }
 
-func (p *Plant) Refresh(t time.Time) bool {
-	if p.LastRefreshedAt != nil && t.Sub(*p.LastRefreshedAt) < minRefreshInterval {
+// Refresh catches the plant up on the time based changes since it was last refreshed.
+// neighbours are the living plants within MaliceReachM of the plant.
+func (p *Plant) Refresh(t time.Time, neighbours ...*Plant) bool {
+	p.loadSoilEffects()
+
+	defer p.loadAvailability(t)
+
+	if !p.DueForRefresh(t) {
 		return p.Alive()
 	}
 
-	p.applyTimeBasedChanges(t)
+	p.applyTimeBasedChanges(t, neighbours)
 	return p.Alive()
 }
 
-func (p *Plant) applyTimeBasedChanges(t time.Time) {
+func (p *Plant) DueForRefresh(t time.Time) bool {
+	return p.LastRefreshedAt == nil || t.Sub(*p.LastRefreshedAt) >= MinRefreshInterval
+}
+
+func (p *Plant) applyTimeBasedChanges(t time.Time, neighbours []*Plant) {
 	if p.Dead {
 		return
 	}
 
-	var lastCalculatedAt time.Time
+	calculatedUntil := p.calculateAndApplyDecay(p.LastCalculatedAt(), t, neighbours)
+	p.LastRefreshedAt = &calculatedUntil
+}
+
+// LastCalculatedAt is the time up to which the plant's time based changes have been applied.
+// It only moves forward a whole decay interval at a time so partial intervals carry over to the next refresh.
+func (p *Plant) LastCalculatedAt() time.Time {
 	if p.LastRefreshedAt != nil {
-		lastCalculatedAt = *p.LastRefreshedAt
-	} else {
-		lastCalculatedAt = p.TimePlanted
+		return *p.LastRefreshedAt
 	}
-
-	p.LastRefreshedAt = &t
-
-	p.calculateAndApplyDecay(lastCalculatedAt, t)
+	return p.TimePlanted
 }
 
-func (p *Plant) calculateAndApplyDecay(fromTime, toTime time.Time) {
+// calculateAndApplyDecay replays every decay interval that ended between fromTime and toTime and
+// returns the time up to which decay has been applied. If the plant dies, it dies at the end of the
+// interval its HP ran out in.
+func (p *Plant) calculateAndApplyDecay(fromTime, toTime time.Time, neighbours []*Plant) time.Time {
 	if p.Dead {
-		return
+		return fromTime
 	}
 
-	elapsed := toTime.Sub(fromTime)
-	totalIntervals := int(elapsed / hpDecayInterval)
+	// the clock can be turned back in development, which must not wind the plant back with it
+	totalIntervals := max(0, int(toTime.Sub(fromTime)/HpDecayInterval))
+	decayPerInterval := p.SoilEffects.DecayMultiplier * p.Tempers.DecayMultiplier()
+	maliceDamage := p.maliceDamageFrom(neighbours)
 
-	if totalIntervals == 0 {
-		return
-	}
+	for i := 0; i < totalIntervals; i++ {
+		intervalTime := fromTime.Add(time.Duration(i+1) * HpDecayInterval)
 
-	intervalsToApply := 0
+		hpLoss := maliceDamage
+		if !p.IsInGracePeriod(intervalTime) {
+			hpLoss += decayPerInterval
+		}
 
-	for i := 0; i < totalIntervals; i++ {
-		intervalTime := fromTime.Add(time.Duration(i+1) * hpDecayInterval)
+		if hpLoss <= 0 {
+			continue
+		}
 
-		inGracePeriod := false
-		if p.GracePeriodEndsAt != nil && intervalTime.Before(*p.GracePeriodEndsAt) {
-			inGracePeriod = true
+		p.recordEvent(&PlantEvent{Type: PlantEventDecay, HpDelta: p.clampHp(p.Hp-hpLoss) - p.Hp, OccurredAt: intervalTime})
+		if !p.changeHp(-hpLoss, intervalTime) {
+			return intervalTime
 		}
+	}
 
-		if !inGracePeriod {
-			intervalsToApply++
+	return fromTime.Add(time.Duration(totalIntervals) * HpDecayInterval)
+}
+
+// maliceDamageFrom sums the HP the plant loses every decay interval to the malice of its neighbours.
+// Unlike neglect, malice is not held off by the watering grace period.
+func (p *Plant) maliceDamageFrom(neighbours []*Plant) float64 {
+	damage := 0.0
+	for _, neighbour := range neighbours {
+		if neighbour == nil || neighbour.ID == p.ID || neighbour.Dead {
+			continue
 		}
+		damage += neighbour.Tempers.MaliceDamage()
 	}
+	return damage
+}
 
-	if intervalsToApply > 0 {
-		p.changeHp(-float64(intervalsToApply))
+// loadSoilEffects derives the plant's soil effects from its soil.
+// Plants without a fully loaded soil are treated as growing in neutral soil.
+func (p *Plant) loadSoilEffects() {
+	effects := NeutralSoilEffects
+	if p.Soil != nil && p.Soil.Type != "" {
+		effects = p.Soil.Effects()
 	}
+	p.SoilEffects = &effects
 }
 
-func (p *Plant) IsInGracePeriod(t time.Time) bool {
-	return p.GracePeriodEndsAt != nil && t.Before(*p.GracePeriodEndsAt)
+func (p *Plant) wateringGracePeriod() time.Duration {
+	return time.Duration(float64(wateringGracePeriod) * p.SoilEffects.GracePeriodMultiplier)
 }
 
-func (p *Plant) CanBeWatered(t time.Time) bool {
-	if p.Dead {
-		return false
-	}
-	return p.LastWateredAt.IsZero() || t.Sub(p.LastWateredAt) >= wateringCooldown
+func (p *Plant) scaleXp(xp int64) int64 {
+	return int64(math.Round(float64(xp) * p.SoilEffects.XpMultiplier * p.Tempers.XpMultiplier()))
 }
 
-func (p *Plant) TimeUntilNextWatering(t time.Time) time.Duration {
-	if p.CanBeWatered(t) {
-		return 0
-	}
-	return wateringCooldown - t.Sub(p.LastWateredAt)
+func (p *Plant) IsInGracePeriod(t time.Time) bool {
+	return p.GracePeriodEndsAt != nil && t.Before(*p.GracePeriodEndsAt)
 }
 
 func (p *Plant) TimeUntilGracePeriodEnds(t time.Time) time.Duration {
@@ -219,21 +284,38 @@ func (p *Plant) Alive() bool {
 	return !p.Dead
 }
 
-func (p *Plant) changeHp(delta float64) bool {
-	p.Hp = math.Max(0, math.Min(100, p.Hp+delta))
+// changeHp changes the plant's HP as of t, which is recorded as its time of death if the HP runs out.
+func (p *Plant) changeHp(delta float64, t time.Time) bool {
+	p.Hp = p.clampHp(p.Hp + delta)
 	if p.Hp == 0 {
-		p.Die(time.Now())
+		p.Die(t)
 	}
 	return p.Alive()
 }
 
+func (p *Plant) clampHp(hp float64) float64 {
+	return math.Max(0, math.Min(100, hp))
+}
+
 func (p *Plant) Die(timeOfDeath time.Time) {
+	p.recordEvent(&PlantEvent{Type: PlantEventDeath, HpDelta: -p.Hp, OccurredAt: timeOfDeath})
 	p.Hp = 0
 	p.Dead = true
 	p.TimeOfDeath = &timeOfDeath
 	p.GracePeriodEndsAt = nil
 }
 
+// Revive brings a dead plant back with the given HP, as if it had just been watered.
+func (p *Plant) Revive(t time.Time, hp float64) {
+	p.Hp = p.clampHp(hp)
+	p.Dead = false
+	p.TimeOfDeath = nil
+	p.LastWateredAt = t
+	p.LastRefreshedAt = &t
+	p.GracePeriodEndsAt = nil
+	p.recordEvent(&PlantEvent{Type: PlantEventRevival, HpDelta: p.Hp, OccurredAt: t})
+}
+
 func generateNickname() string {
 	adjectives := []string{
 		"Wiggly", "Sparkle", "Fuzzy", "Giggly", "Dapper", "Sneaky", "Wobble",
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type PlantAction int
+
+const (
+	PlantActionWater PlantAction = iota + 1
+	PlantActionFertilise
+	PlantActionPrune
+	PlantActionTalk
+)
+
+const (
+	wateringPlantXpGain = 30
+	wateringPlantHpGain = 5
+	wateringGracePeriod = 4 * time.Hour
+	wateringCooldown    = 3 * time.Hour
+
+	fertilisingPlantXpGain = 20
+	fertilisingPlantHpGain = 10 // scaled by the soil's SoilEffects.FertiliserMultiplier
+	fertilisingDreadRelief = 0.5
+	fertilisingCooldown    = 24 * time.Hour
+
+	pruningPlantXpGain  = 25
+	pruningPlantHpGain  = 3
+	pruningMaliceRelief = 0.5
+	pruningCooldown     = 48 * time.Hour
+
+	talkingPlantXpGain = 5
+	talkingFrolicGain  = 0.25
+	talkingWoeRelief   = 0.25
+	talkingCooldown    = 1 * time.Hour
+)
+
+var (
+	ErrPlantInCooldown          = errors.New("plant is in cooldown mode") // returned when watering too soon
+	ErrPlantFertiliseInCooldown = errors.New("plant was fertilised too recently")
+	ErrPlantPruneInCooldown     = errors.New("plant was pruned too recently")
+	ErrPlantTalkInCooldown      = errors.New("plant was talked to too recently")
+	ErrUnknownPlantAction       = errors.New("unknown plant action")
+)
+
+func ValidPlantAction(action int) bool {
+	m := map[PlantAction]bool{
+		PlantActionWater:     true,
+		PlantActionFertilise: true,
+		PlantActionPrune:     true,
+		PlantActionTalk:      true,
+	}
+	return m[PlantAction(action)]
+}
+
+// PlantActionAvailability is how many seconds are left until each action can next be performed on the plant.
+// 0 means the action is available now.
+type PlantActionAvailability struct {
+	TimeUntilNextWatering    int64 `json:"timeUntilNextWatering"`
+	TimeUntilNextFertilising int64 `json:"timeUntilNextFertilising"`
+	TimeUntilNextPruning     int64 `json:"timeUntilNextPruning"`
+	TimeUntilNextTalk        int64 `json:"timeUntilNextTalk"`
+}
+
+func (p *Plant) loadAvailability(t time.Time) {
+	p.Availability = &PlantActionAvailability{
+		TimeUntilNextWatering:    int64(p.TimeUntilNextWatering(t).Seconds()),
+		TimeUntilNextFertilising: int64(p.TimeUntilNextFertilising(t).Seconds()),
+		TimeUntilNextPruning:     int64(p.TimeUntilNextPruning(t).Seconds()),
+		TimeUntilNextTalk:        int64(p.TimeUntilNextTalk(t).Seconds()),
+	}
+}
+
+// Watering gives HP and XP and holds off decay for a grace period that depends on the soil's water retention.
+func (p *Plant) water(t time.Time) (int64, error) {
+	if !p.CanBeWatered(t) {
+		return 0, ErrPlantInCooldown
+	}
+
+	xp := p.scaleXp(wateringPlantXpGain)
+	p.addXp(xp)
+	p.changeHp(wateringPlantHpGain, t)
+	p.LastWateredAt = t
+
+	gracePeriodEnd := t.Add(p.wateringGracePeriod())
+	p.GracePeriodEndsAt = &gracePeriodEnd
+	return xp, nil
+}
+
+// Fertilising gives HP, more so in nutrient poor soils, and eases the plant's dread.
+func (p *Plant) fertilise(t time.Time) (int64, error) {
+	if !p.CanBeFertilised(t) {
+		return 0, ErrPlantFertiliseInCooldown
+	}
+
+	xp := p.scaleXp(fertilisingPlantXpGain)
+	p.addXp(xp)
+	p.changeHp(fertilisingPlantHpGain*p.SoilEffects.FertiliserMultiplier, t)
+	p.Tempers.change(0, 0, -fertilisingDreadRelief, 0)
+	p.LastFertilisedAt = &t
+	return xp, nil
+}
+
+// Pruning gives a little HP and curbs the plant's malice towards its neighbours.
+func (p *Plant) prune(t time.Time) (int64, error) {
+	if !p.CanBePruned(t) {
+		return 0, ErrPlantPruneInCooldown
+	}
+
+	xp := p.scaleXp(pruningPlantXpGain)
+	p.addXp(xp)
+	p.changeHp(pruningPlantHpGain, t)
+	p.Tempers.change(0, 0, 0, -pruningMaliceRelief)
+	p.LastPrunedAt = &t
+	return xp, nil
+}
+
+// Talking to the plant gives no HP but lifts its mood, raising frolic and easing woe.
+func (p *Plant) talk(t time.Time) (int64, error) {
+	if !p.CanBeTalkedTo(t) {
+		return 0, ErrPlantTalkInCooldown
+	}
+
+	xp := p.scaleXp(talkingPlantXpGain)
+	p.addXp(xp)
+	p.Tempers.change(-talkingWoeRelief, talkingFrolicGain, 0, 0)
+	p.LastTalkedToAt = &t
+	return xp, nil
+}
+
+func (p *Plant) CanBeWatered(t time.Time) bool {
+	if p.Dead {
+		return false
+	}
+	return p.LastWateredAt.IsZero() || t.Sub(p.LastWateredAt) >= wateringCooldown
+}
+
+func (p *Plant) CanBeFertilised(t time.Time) bool {
+	return !p.Dead && cooldownOver(p.LastFertilisedAt, fertilisingCooldown, t)
+}
+
+func (p *Plant) CanBePruned(t time.Time) bool {
+	return !p.Dead && cooldownOver(p.LastPrunedAt, pruningCooldown, t)
+}
+
+func (p *Plant) CanBeTalkedTo(t time.Time) bool {
+	return !p.Dead && cooldownOver(p.LastTalkedToAt, talkingCooldown, t)
+}
+
+// TimeUntilNextWatering is 0 for a dead plant, which can never be watered again.
+func (p *Plant) TimeUntilNextWatering(t time.Time) time.Duration {
+	if !p.Alive() || p.CanBeWatered(t) {
+		return 0
+	}
+	return wateringCooldown - t.Sub(p.LastWateredAt)
+}
+
+func (p *Plant) TimeUntilNextFertilising(t time.Time) time.Duration {
+	return timeUntilCooldownOver(p.LastFertilisedAt, fertilisingCooldown, t)
+}
+
+func (p *Plant) TimeUntilNextPruning(t time.Time) time.Duration {
+	return timeUntilCooldownOver(p.LastPrunedAt, pruningCooldown, t)
+}
+
+func (p *Plant) TimeUntilNextTalk(t time.Time) time.Duration {
+	return timeUntilCooldownOver(p.LastTalkedToAt, talkingCooldown, t)
+}
+
+func cooldownOver(lastDoneAt *time.Time, cooldown time.Duration, t time.Time) bool {
+	return lastDoneAt == nil || t.Sub(*lastDoneAt) >= cooldown
+}
+
+func timeUntilCooldownOver(lastDoneAt *time.Time, cooldown time.Duration, t time.Time) time.Duration {
+	if cooldownOver(lastDoneAt, cooldown, t) {
+		return 0
+	}
+	return cooldown - t.Sub(*lastDoneAt)
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCareActions(t *testing.T) {
+	baseTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newPlant := func(soilMeta SoilMeta) *Plant {
+		lastRefreshedAt := baseTime
+		return &Plant{
+			Hp:              50.0,
+			TimePlanted:     baseTime,
+			LastRefreshedAt: &lastRefreshedAt,
+			Soil:            &Soil{SoilMeta: soilMeta},
+			Tempers:         &Tempers{Woe: 3, Frolic: 3, Dread: 3, Malice: 3},
+			LevelMeta:       NewLeveLMeta(1, 0),
+		}
+	}
+
+	t.Run("each action has its own cooldown error", func(t *testing.T) {
+		tests := []struct {
+			action PlantAction
+			err    error
+		}{
+			{PlantActionWater, ErrPlantInCooldown},
+			{PlantActionFertilise, ErrPlantFertiliseInCooldown},
+			{PlantActionPrune, ErrPlantPruneInCooldown},
+			{PlantActionTalk, ErrPlantTalkInCooldown},
+		}
+
+		for _, tt := range tests {
+			plant := newPlant(DefaultSoilMetaLoam)
+
+			_, err := plant.Action(tt.action, baseTime)
+			assert.NoError(t, err)
+
+			_, err = plant.Action(tt.action, baseTime.Add(time.Minute))
+			assert.ErrorIs(t, err, tt.err)
+		}
+	})
+
+	t.Run("actions do not share cooldowns", func(t *testing.T) {
+		plant := newPlant(DefaultSoilMetaLoam)
+
+		for _, action := range []PlantAction{PlantActionWater, PlantActionFertilise, PlantActionPrune, PlantActionTalk} {
+			_, err := plant.Action(action, baseTime)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("fertilising helps plants in poor soil more", func(t *testing.T) {
+		sandyPlant := newPlant(DefaultSoilMetaSandy)
+		clayPlant := newPlant(DefaultSoilMetaClay)
+
+		//nolint:errcheck
+		sandyPlant.Action(PlantActionFertilise, baseTime)
+		//nolint:errcheck
+		clayPlant.Action(PlantActionFertilise, baseTime)
+
+		assert.Greater(t, sandyPlant.Hp, clayPlant.Hp)
+		assert.Equal(t, 2.5, sandyPlant.Tempers.Dread)
+	})
+
+	t.Run("pruning curbs malice", func(t *testing.T) {
+		plant := newPlant(DefaultSoilMetaLoam)
+
+		//nolint:errcheck
+		plant.Action(PlantActionPrune, baseTime)
+
+		assert.Equal(t, 53.0, plant.Hp)
+		assert.Equal(t, 2.5, plant.Tempers.Malice)
+		assert.NotNil(t, plant.LastPrunedAt)
+	})
+
+	t.Run("talking lifts the plant's mood without healing it", func(t *testing.T) {
+		plant := newPlant(DefaultSoilMetaLoam)
+
+		//nolint:errcheck
+		plant.Action(PlantActionTalk, baseTime)
+
+		assert.Equal(t, 50.0, plant.Hp)
+		assert.Equal(t, 2.75, plant.Tempers.Woe)
+		assert.Equal(t, 3.25, plant.Tempers.Frolic)
+		assert.Greater(t, plant.XP, int64(0))
+	})
+
+	t.Run("tempers stay within range", func(t *testing.T) {
+		plant := newPlant(DefaultSoilMetaLoam)
+		plant.Tempers = &Tempers{Woe: 1, Frolic: 5, Dread: 1, Malice: 1}
+
+		//nolint:errcheck
+		plant.Action(PlantActionTalk, baseTime)
+
+		assert.Equal(t, 1.0, plant.Tempers.Woe)
+		assert.Equal(t, 5.0, plant.Tempers.Frolic)
+	})
+
+	t.Run("availability reports the time left on each cooldown", func(t *testing.T) {
+		plant := newPlant(DefaultSoilMetaLoam)
+
+		//nolint:errcheck
+		plant.Action(PlantActionFertilise, baseTime)
+		plant.Refresh(baseTime.Add(time.Hour))
+
+		assert.Equal(t, int64(0), plant.Availability.TimeUntilNextWatering)
+		assert.Equal(t, int64((23 * time.Hour).Seconds()), plant.Availability.TimeUntilNextFertilising)
+		assert.Equal(t, int64(0), plant.Availability.TimeUntilNextPruning)
+		assert.Equal(t, int64(0), plant.Availability.TimeUntilNextTalk)
+	})
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+type PlantEventType string
+
+const (
+	PlantEventAction  PlantEventType = "action"
+	PlantEventDecay   PlantEventType = "decay"
+	PlantEventLevelUp PlantEventType = "level_up"
+	PlantEventDeath   PlantEventType = "death"
+	PlantEventRevival PlantEventType = "revival"
+)
+
+// PlantEvent is an entry in a plant's history.
+// Events caused by a player's action have Action set and are attributed to the player and where they were.
+type PlantEvent struct {
+	ID         int64          `json:"id"`
+	PlantID    string         `json:"plantID"`
+	Type       PlantEventType `json:"type"`
+	Action     PlantAction    `json:"action,omitempty"`
+	ActorID    *string        `json:"actorID"`
+	Location   *Coordinates   `json:"location,omitempty"`
+	HpDelta    float64        `json:"hpDelta"`
+	XpDelta    int64          `json:"xpDelta"`
+	Level      int64          `json:"level,omitempty"` // the level reached by a level up
+	OccurredAt time.Time      `json:"occurredAt"`
+}
+
+func (p *Plant) recordEvent(event *PlantEvent) {
+	event.PlantID = p.ID
+	p.events = append(p.events, event)
+}
+
+// PopEvents returns the events recorded since they were last popped.
+func (p *Plant) PopEvents() []*PlantEvent {
+	events := p.events
+	p.events = nil
+	return events
+}
@@ -171,6 +171,47 @@ func TestDecayMechanics(t *testing.T) {
 		assert.NotNil(t, plant.TimeOfDeath)
 	})
 
+	t.Run("plant dies at the interval its hp runs out in", func(t *testing.T) {
+		plant := &Plant{
+			Hp:          2.0,
+			TimePlanted: baseTime,
+		}
+
+		refreshTime := baseTime.Add(7 * 24 * time.Hour)
+		plant.Refresh(refreshTime)
+
+		assert.True(t, plant.Dead)
+		assert.Equal(t, baseTime.Add(2*HpDecayInterval), *plant.TimeOfDeath)
+	})
+
+	t.Run("partial intervals carry over to the next refresh", func(t *testing.T) {
+		plant := &Plant{
+			Hp:          100.0,
+			TimePlanted: baseTime,
+		}
+
+		for i := 1; i <= 4; i++ {
+			plant.Refresh(baseTime.Add(time.Duration(i) * time.Hour))
+		}
+
+		assert.Equal(t, 99.0, plant.Hp)
+		assert.Equal(t, baseTime.Add(HpDecayInterval), *plant.LastRefreshedAt)
+	})
+
+	t.Run("refreshing before the last calculated time changes nothing", func(t *testing.T) {
+		plant := &Plant{
+			Hp:          100.0,
+			TimePlanted: baseTime,
+		}
+
+		plant.Refresh(baseTime.Add(-8 * time.Hour))
+		assert.Equal(t, 100.0, plant.Hp)
+		assert.Equal(t, baseTime, plant.LastCalculatedAt())
+
+		plant.Refresh(baseTime.Add(8 * time.Hour))
+		assert.Equal(t, 98.0, plant.Hp)
+	})
+
 	t.Run("refresh skipped if less than 5 minutes since last refresh", func(t *testing.T) {
 		lastRefresh := baseTime
 		plant := &Plant{
@@ -187,6 +228,59 @@ func TestDecayMechanics(t *testing.T) {
 	})
 }
 
+func TestSoilAwareCare(t *testing.T) {
+	baseTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("clay soil extends the watering grace period", func(t *testing.T) {
+		plant := &Plant{
+			Hp:          50.0,
+			Soil:        &Soil{SoilMeta: DefaultSoilMetaClay},
+			TimePlanted: baseTime,
+		}
+
+		waterTime := baseTime.Add(1 * time.Hour)
+		//nolint:errcheck
+		plant.Action(PlantActionWater, waterTime)
+
+		assert.NotNil(t, plant.SoilEffects)
+		assert.True(t, plant.GracePeriodEndsAt.After(waterTime.Add(wateringGracePeriod)))
+	})
+
+	t.Run("sandy soil decays faster than neutral soil", func(t *testing.T) {
+		sandyPlant := &Plant{
+			Hp:          100.0,
+			Soil:        &Soil{SoilMeta: DefaultSoilMetaSandy},
+			TimePlanted: baseTime,
+		}
+		neutralPlant := &Plant{
+			Hp:          100.0,
+			TimePlanted: baseTime,
+		}
+
+		refreshTime := baseTime.Add(24 * time.Hour)
+		sandyPlant.Refresh(refreshTime)
+		neutralPlant.Refresh(refreshTime)
+
+		assert.Equal(t, 94.0, neutralPlant.Hp)
+		assert.InDelta(t, 100.0-6*sandyPlant.SoilEffects.DecayMultiplier, sandyPlant.Hp, 1e-9)
+		assert.Less(t, sandyPlant.Hp, neutralPlant.Hp)
+	})
+
+	t.Run("nutrient rich soil gives more xp from watering", func(t *testing.T) {
+		plant := &Plant{
+			Hp:          50.0,
+			Soil:        &Soil{SoilMeta: SoilMeta{Type: SoilTypeLoam, WaterRetention: 0.55, NutrientRichness: 1.0}},
+			LevelMeta:   NewLeveLMeta(1, 0),
+			TimePlanted: baseTime,
+		}
+
+		//nolint:errcheck
+		plant.Action(PlantActionWater, baseTime.Add(1*time.Hour))
+
+		assert.Equal(t, int64(36), plant.XP)
+	})
+}
+
 func TestGracePeriodMechanics(t *testing.T) {
 	baseTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
 
@@ -287,6 +381,15 @@ func TestHelperMethods(t *testing.T) {
 		assert.Equal(t, time.Duration(0), remaining)
 	})
 
+	t.Run("TimeUntilNextWatering is never negative for a dead plant", func(t *testing.T) {
+		plant := &Plant{
+			Dead:          true,
+			LastWateredAt: baseTime,
+		}
+
+		assert.Equal(t, time.Duration(0), plant.TimeUntilNextWatering(baseTime.Add(24*time.Hour)))
+	})
+
 	t.Run("TimeUntilGracePeriodEnds calculates correctly", func(t *testing.T) {
 		gracePeriodEnd := baseTime.Add(4 * time.Hour)
 		plant := &Plant{GracePeriodEndsAt: &gracePeriodEnd}
@@ -303,10 +406,12 @@ func TestHelperMethods(t *testing.T) {
 }
 
 func TestChangeHp(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
 	t.Run("increase hp within limits", func(t *testing.T) {
 		plant := &Plant{Hp: 50.0}
 
-		plant.changeHp(20.0)
+		plant.changeHp(20.0, now)
 
 		assert.Equal(t, 70.0, plant.Hp)
 		assert.False(t, plant.Dead)
@@ -315,7 +420,7 @@ func TestChangeHp(t *testing.T) {
 	t.Run("decrease hp within limits", func(t *testing.T) {
 		plant := &Plant{Hp: 50.0}
 
-		plant.changeHp(-30.0)
+		plant.changeHp(-30.0, now)
 
 		assert.Equal(t, 20.0, plant.Hp)
 		assert.False(t, plant.Dead)
@@ -325,17 +430,26 @@ func TestChangeHp(t *testing.T) {
 	t.Run("decrease plant hp to exactly 0", func(t *testing.T) {
 		plant := &Plant{Hp: 10.0}
 
-		plant.changeHp(-10.0)
+		plant.changeHp(-10.0, now)
 
 		assert.Equal(t, 0.0, plant.Hp)
 		assert.True(t, plant.Dead)
 		assert.NotNil(t, plant.TimeOfDeath)
 	})
 
+	t.Run("plant dies at the time the hp ran out", func(t *testing.T) {
+		plant := &Plant{Hp: 10.0}
+
+		plant.changeHp(-10.0, now)
+
+		assert.True(t, plant.Dead)
+		assert.Equal(t, now, *plant.TimeOfDeath)
+	})
+
 	t.Run("decrease plant hp beyond 0", func(t *testing.T) {
 		plant := &Plant{Hp: 10.0}
 
-		plant.changeHp(-15.0)
+		plant.changeHp(-15.0, now)
 
 		assert.Equal(t, 0.0, plant.Hp)
 		assert.True(t, plant.Dead)
@@ -345,7 +459,7 @@ func TestChangeHp(t *testing.T) {
 	t.Run("increase plant hp above 100", func(t *testing.T) {
 		plant := &Plant{Hp: 85.0}
 
-		plant.changeHp(20.0)
+		plant.changeHp(20.0, now)
 
 		assert.Equal(t, 100.0, plant.Hp)
 		assert.False(t, plant.Dead)
@@ -403,3 +517,101 @@ func TestCasualCareScenario(t *testing.T) {
 		assert.Greater(t, plant.Hp, 90.0)
 	})
 }
+
+func TestPlantEvents(t *testing.T) {
+	baseTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("each decay interval is recorded", func(t *testing.T) {
+		plant := &Plant{Hp: 100.0, TimePlanted: baseTime}
+
+		plant.Refresh(baseTime.Add(3 * HpDecayInterval))
+
+		events := plant.PopEvents()
+		assert.Len(t, events, 3)
+		for i, event := range events {
+			assert.Equal(t, PlantEventDecay, event.Type)
+			assert.Equal(t, -1.0, event.HpDelta)
+			assert.Equal(t, baseTime.Add(time.Duration(i+1)*HpDecayInterval), event.OccurredAt)
+		}
+		assert.Empty(t, plant.PopEvents())
+	})
+
+	t.Run("death follows the decay that caused it", func(t *testing.T) {
+		plant := &Plant{Hp: 1.5, TimePlanted: baseTime}
+
+		plant.Refresh(baseTime.Add(7 * 24 * time.Hour))
+
+		events := plant.PopEvents()
+		assert.Len(t, events, 3)
+		assert.Equal(t, PlantEventDecay, events[1].Type)
+		assert.Equal(t, -0.5, events[1].HpDelta)
+		assert.Equal(t, PlantEventDeath, events[2].Type)
+		assert.Equal(t, *plant.TimeOfDeath, events[2].OccurredAt)
+	})
+
+	t.Run("actions and the level ups they cause are recorded", func(t *testing.T) {
+		plant := &Plant{Hp: 50.0, TimePlanted: baseTime, LevelMeta: NewLeveLMeta(1, xpRequiredForLevel(2)-1)}
+
+		//nolint:errcheck
+		plant.Action(PlantActionWater, baseTime.Add(time.Hour))
+
+		events := plant.PopEvents()
+		assert.Len(t, events, 2)
+		assert.Equal(t, PlantEventAction, events[0].Type)
+		assert.Equal(t, PlantActionWater, events[0].Action)
+		assert.Equal(t, float64(wateringPlantHpGain), events[0].HpDelta)
+		assert.Equal(t, int64(wateringPlantXpGain), events[0].XpDelta)
+		assert.Equal(t, PlantEventLevelUp, events[1].Type)
+		assert.Equal(t, int64(2), events[1].Level)
+	})
+
+	t.Run("revival is recorded and decay starts again from it", func(t *testing.T) {
+		plant := &Plant{Hp: 1.0, TimePlanted: baseTime}
+		plant.Die(baseTime)
+		plant.PopEvents()
+
+		reviveTime := baseTime.Add(30 * 24 * time.Hour)
+		plant.Revive(reviveTime, 50)
+
+		assert.True(t, plant.Alive())
+		assert.Nil(t, plant.TimeOfDeath)
+		events := plant.PopEvents()
+		assert.Len(t, events, 1)
+		assert.Equal(t, PlantEventRevival, events[0].Type)
+		assert.Equal(t, 50.0, events[0].HpDelta)
+
+		plant.Refresh(reviveTime.Add(HpDecayInterval))
+		assert.Equal(t, 49.0, plant.Hp)
+	})
+}
+
+func TestPlantLocationVisibility(t *testing.T) {
+	centre := Coordinates{Lat: 40.782865, Lon: -73.965355}
+	newPlant := func(shareExactLocation bool) *Plant {
+		return &Plant{
+			OwnerID:            "owner",
+			ShareExactLocation: shareExactLocation,
+			Soil:               &Soil{CircleMeta: NewCircleMeta(centre, SoilRadiusMSmall)},
+			CircleMeta:         NewCircleMeta(centre, PlantInteractionRadius),
+		}
+	}
+
+	t.Run("only the owner sees where a plant is by default", func(t *testing.T) {
+		plant := newPlant(false)
+		assert.True(t, plant.LocationVisibleTo("owner"))
+		assert.False(t, plant.LocationVisibleTo("someone else"))
+	})
+
+	t.Run("everyone sees where a shared plant is", func(t *testing.T) {
+		plant := newPlant(true)
+		assert.True(t, plant.LocationVisibleTo("someone else"))
+	})
+
+	t.Run("hiding the location hides the soil too", func(t *testing.T) {
+		plant := newPlant(false)
+		plant.HideLocation()
+		assert.Equal(t, Coordinates{}, plant.Centre())
+		assert.Nil(t, plant.Soil)
+		assert.Equal(t, float64(PlantInteractionRadius), plant.RadiusM())
+	})
+}
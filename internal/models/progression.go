@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+const (
+	playerXpForWatering          = 10
+	playerXpForFertilising       = 10
+	playerXpForPruning           = 10
+	playerXpForTalking           = 2
+	playerXpForCommunityWatering = 5
+	playerXpForPlanting          = 20
+	playerXpPerPlantLevel        = 25
+)
+
+type survivalMilestone struct {
+	age time.Duration
+	xp  int64
+}
+
+// XP given to a plant's owner the first time the plant has been alive for age
+var plantSurvivalMilestones = []survivalMilestone{
+	{age: 7 * 24 * time.Hour, xp: 50},
+	{age: 30 * 24 * time.Hour, xp: 200},
+	{age: 100 * 24 * time.Hour, xp: 500},
+}
+
+type LevelUpEvent struct {
+	PreviousLevel int64 `json:"previousLevel"`
+	NewLevel      int64 `json:"newLevel"`
+}
+
+// PlayerProgress is the result of a player gaining XP.
+// LevelUp is nil unless the XP gained was enough to take the player to a new level.
+type PlayerProgress struct {
+	XpGained        int64          `json:"xpGained"`
+	LevelUp         *LevelUpEvent  `json:"levelUp"`
+	NewAchievements []*Achievement `json:"newAchievements"`
+	LevelMeta
+}
+
+func PlayerXpForPlantAction(action PlantAction) int64 {
+	switch action {
+	case PlantActionWater:
+		return playerXpForWatering
+	case PlantActionFertilise:
+		return playerXpForFertilising
+	case PlantActionPrune:
+		return playerXpForPruning
+	case PlantActionTalk:
+		return playerXpForTalking
+	default:
+		return 0
+	}
+}
+
+// PlayerXpForCommunityWatering is the XP a player is credited with for watering someone else's plant.
+func PlayerXpForCommunityWatering() int64 {
+	return playerXpForCommunityWatering
+}
+
+func PlayerXpForPlanting() int64 {
+	return playerXpForPlanting
+}
+
+func PlayerXpForPlantLevelUps(previousLevel, newLevel int64) int64 {
+	if newLevel <= previousLevel {
+		return 0
+	}
+	return (newLevel - previousLevel) * playerXpPerPlantLevel
+}
+
+// PlayerXpForSurvivalMilestones returns the XP for the survival milestones the plant reached after from and up to to.
+// A dead plant only counts milestones reached before it died.
+func PlayerXpForSurvivalMilestones(p *Plant, from, to time.Time) int64 {
+	if p.Dead && p.TimeOfDeath != nil && p.TimeOfDeath.Before(to) {
+		to = *p.TimeOfDeath
+	}
+
+	var xp int64
+	for _, milestone := range plantSurvivalMilestones {
+		reachedAt := p.TimePlanted.Add(milestone.age)
+		if reachedAt.After(from) && !reachedAt.After(to) {
+			xp += milestone.xp
+		}
+	}
+	return xp
+}
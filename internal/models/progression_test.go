@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserGainXp(t *testing.T) {
+	t.Run("gaining xp without levelling up has no level up event", func(t *testing.T) {
+		user := &User{LevelMeta: NewLeveLMeta(1, 0)}
+
+		progress := user.GainXp(playerXpForWatering)
+
+		assert.Equal(t, int64(playerXpForWatering), progress.XpGained)
+		assert.Equal(t, int64(1), progress.Level)
+		assert.Equal(t, int64(playerXpForWatering), progress.XP)
+		assert.Nil(t, progress.LevelUp)
+	})
+
+	t.Run("gaining enough xp includes a level up event", func(t *testing.T) {
+		user := &User{LevelMeta: NewLeveLMeta(1, 0)}
+
+		progress := user.GainXp(xpRequiredForLevel(2) + xpRequiredForLevel(3))
+
+		assert.NotNil(t, progress.LevelUp)
+		assert.Equal(t, int64(1), progress.LevelUp.PreviousLevel)
+		assert.Equal(t, int64(3), progress.LevelUp.NewLevel)
+		assert.Equal(t, int64(3), user.Level)
+	})
+}
+
+func TestPlayerXpForPlantLevelUps(t *testing.T) {
+	assert.Equal(t, int64(0), PlayerXpForPlantLevelUps(2, 2))
+	assert.Equal(t, int64(playerXpPerPlantLevel), PlayerXpForPlantLevelUps(2, 3))
+	assert.Equal(t, int64(3*playerXpPerPlantLevel), PlayerXpForPlantLevelUps(1, 4))
+}
+
+func TestPlayerXpForSurvivalMilestones(t *testing.T) {
+	timePlanted := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	t.Run("no milestones reached", func(t *testing.T) {
+		plant := &Plant{TimePlanted: timePlanted}
+
+		xp := PlayerXpForSurvivalMilestones(plant, timePlanted, timePlanted.Add(6*day))
+
+		assert.Equal(t, int64(0), xp)
+	})
+
+	t.Run("milestone is only counted once", func(t *testing.T) {
+		plant := &Plant{TimePlanted: timePlanted}
+
+		first := PlayerXpForSurvivalMilestones(plant, timePlanted, timePlanted.Add(8*day))
+		second := PlayerXpForSurvivalMilestones(plant, timePlanted.Add(8*day), timePlanted.Add(9*day))
+
+		assert.Equal(t, int64(50), first)
+		assert.Equal(t, int64(0), second)
+	})
+
+	t.Run("several milestones reached in one go", func(t *testing.T) {
+		plant := &Plant{TimePlanted: timePlanted}
+
+		xp := PlayerXpForSurvivalMilestones(plant, timePlanted, timePlanted.Add(31*day))
+
+		assert.Equal(t, int64(250), xp)
+	})
+
+	t.Run("milestones after death are not counted", func(t *testing.T) {
+		timeOfDeath := timePlanted.Add(10 * day)
+		plant := &Plant{TimePlanted: timePlanted, Dead: true, TimeOfDeath: &timeOfDeath}
+
+		xp := PlayerXpForSurvivalMilestones(plant, timePlanted, timePlanted.Add(40*day))
+
+		assert.Equal(t, int64(50), xp)
+	})
+
+	t.Run("milestone partway through a decay interval is only counted once across an action and a refresh", func(t *testing.T) {
+		lastRefreshedAt := timePlanted.Add(7*day - 2*time.Hour)
+		plant := &Plant{
+			Hp:              100.0,
+			Soil:            &Soil{SoilMeta: DefaultSoilMetaLoam},
+			LevelMeta:       NewLeveLMeta(1, 0),
+			TimePlanted:     timePlanted,
+			LastRefreshedAt: &lastRefreshedAt,
+		}
+
+		// the action comes before the interval the milestone is in has ended, so decay isn't calculated past it yet
+		lastCalculatedAt := plant.LastCalculatedAt()
+		_, err := plant.Action(PlantActionWater, timePlanted.Add(7*day+time.Hour))
+		assert.NoError(t, err)
+		xp := PlayerXpForSurvivalMilestones(plant, lastCalculatedAt, plant.LastCalculatedAt())
+
+		lastCalculatedAt = plant.LastCalculatedAt()
+		plant.Refresh(timePlanted.Add(7*day + 3*time.Hour))
+		xp += PlayerXpForSurvivalMilestones(plant, lastCalculatedAt, plant.LastCalculatedAt())
+
+		assert.Equal(t, int64(50), xp)
+	})
+}
@@ -0,0 +1,34 @@
+package models
+
+import "errors"
+
+var ErrInvalidRole = errors.New("invalid role")
+
+// Role is what a user is trusted to do. Every role can do everything the roles below it can.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether the role can do everything the other role can.
+func (r Role) AtLeast(other Role) bool {
+	rank, ok := roleRanks[r]
+	if !ok {
+		return false
+	}
+	return rank >= roleRanks[other]
+}
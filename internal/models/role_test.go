@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole(t *testing.T) {
+	t.Run("roles include the ones below them", func(t *testing.T) {
+		assert.True(t, RoleAdmin.AtLeast(RoleModerator))
+		assert.True(t, RoleModerator.AtLeast(RoleModerator))
+		assert.True(t, RoleModerator.AtLeast(RoleUser))
+	})
+
+	t.Run("roles don't include the ones above them", func(t *testing.T) {
+		assert.False(t, RoleUser.AtLeast(RoleModerator))
+		assert.False(t, RoleModerator.AtLeast(RoleAdmin))
+	})
+
+	t.Run("unknown roles can't do anything", func(t *testing.T) {
+		assert.False(t, Role("root").Valid())
+		assert.False(t, Role("root").AtLeast(RoleUser))
+	})
+}
@@ -25,6 +25,13 @@ type Seed struct {
 	SeedMeta
 }
 
+// SeedRequest is a time the user asked for new seeds and whether they were given any.
+type SeedRequest struct {
+	RequestedAt time.Time `json:"requestedAt"`
+	Fulfilled   bool      `json:"fulfilled"`
+	SeedCount   float64   `json:"seedCount"`
+}
+
 type SeedGroup struct {
 	BotanicalName string  `json:"botanicalName"`
 	Count         int     `json:"count"`
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// maxUserAgentLength is how much of a client's user agent is kept for its sessions
+const maxUserAgentLength = 512
+
+// ClientInfo describes the client a refresh token was issued to.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+func NewClientInfo(userAgent, ipAddress string) ClientInfo {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return ClientInfo{UserAgent: userAgent, IPAddress: ipAddress}
+}
+
+// Session is a login on one client. Its ID is the family ID of the refresh tokens rotated from that login,
+// and it was last used when its newest refresh token was issued.
+type Session struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	Current    bool      `json:"current"`
+}
@@ -49,6 +49,46 @@ var (
 	}
 )
 
+// SoilEffects are the multipliers a soil applies to the care mechanics of the plants growing in it.
+// A soil with the same properties as DefaultSoilMetaLoam has no effect (every multiplier is 1).
+type SoilEffects struct {
+	GracePeriodMultiplier float64 `json:"gracePeriodMultiplier"` // scales how long a watering protects the plant from decay
+	DecayMultiplier       float64 `json:"decayMultiplier"`       // scales the HP lost every decay interval
+	XpMultiplier          float64 `json:"xpMultiplier"`          // scales the XP gained from care
+	FertiliserMultiplier  float64 `json:"fertiliserMultiplier"`  // scales the HP gained from fertilising, poorer soils respond more
+}
+
+const (
+	waterRetentionGracePeriodScale  = 1.0
+	waterRetentionDecayScale        = 0.5
+	nutrientRichnessXpScale         = 0.8
+	nutrientRichnessFertiliserScale = 1.0
+)
+
+var NeutralSoilEffects = SoilEffects{
+	GracePeriodMultiplier: 1.0,
+	DecayMultiplier:       1.0,
+	XpMultiplier:          1.0,
+	FertiliserMultiplier:  1.0,
+}
+
+func (s SoilMeta) Effects() SoilEffects {
+	waterRetentionDelta := s.WaterRetention - DefaultSoilMetaLoam.WaterRetention
+	nutrientRichnessDelta := s.NutrientRichness - DefaultSoilMetaLoam.NutrientRichness
+
+	return SoilEffects{
+		GracePeriodMultiplier: roundMultiplier(1 + waterRetentionDelta*waterRetentionGracePeriodScale),
+		DecayMultiplier:       roundMultiplier(1 - waterRetentionDelta*waterRetentionDecayScale),
+		XpMultiplier:          roundMultiplier(1 + nutrientRichnessDelta*nutrientRichnessXpScale),
+		FertiliserMultiplier:  roundMultiplier(1 - nutrientRichnessDelta*nutrientRichnessFertiliserScale),
+	}
+}
+
+// rounds to 3 decimal places so the multipliers shown to players are stable
+func roundMultiplier(m float64) float64 {
+	return math.Round(m*1000) / 1000
+}
+
 type Soil struct {
 	ID        string     `json:"id"`
 	CreatedAt *time.Time `json:"createdAt"`
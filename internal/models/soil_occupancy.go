@@ -0,0 +1,139 @@
+package models
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+const (
+	// PlantSpacingM is how close together the centres of two living plants in the same soil can be.
+	PlantSpacingM = PlantInteractionRadius + 0.1
+
+	// soilOccupancyGridStepM is how far apart the places tried for a plant are
+	soilOccupancyGridStepM = 2.0
+	// soilOccupancyMarginM keeps free spots clear of the limits in case the database measures distances slightly differently
+	soilOccupancyMarginM = 0.5
+	// soilCentreAreaFraction is how much of a soil's radius its centre area reaches out to
+	soilCentreAreaFraction = 1.0 / 3
+)
+
+// SoilArea is a coarse part of a soil. Free room is only ever given by area, since the gaps between exact free spots
+// would give away where the plants of owners who don't share their location are.
+type SoilArea string
+
+const (
+	SoilAreaCentre    SoilArea = "centre"
+	SoilAreaNorthEast SoilArea = "north-east"
+	SoilAreaSouthEast SoilArea = "south-east"
+	SoilAreaSouthWest SoilArea = "south-west"
+	SoilAreaNorthWest SoilArea = "north-west"
+)
+
+var soilAreas = []SoilArea{SoilAreaCentre, SoilAreaNorthEast, SoilAreaSouthEast, SoilAreaSouthWest, SoilAreaNorthWest}
+
+// SoilOccupancy is how much room a soil has for plants. FreeSpotCount plants can be planted right now, somewhere in FreeAreas,
+// although planting one may use up the room of another.
+type SoilOccupancy struct {
+	PlantCount    int        `json:"plantCount"`
+	PlantCapacity int        `json:"plantCapacity"`
+	FreeSpotCount int        `json:"freeSpotCount"`
+	FreeAreas     []SoilArea `json:"freeAreas"`
+}
+
+type SoilWithOccupancy struct {
+	Soil
+	Occupancy SoilOccupancy `json:"occupancy"`
+}
+
+// plantSpot is a place a plant could go and the area of the soil it is in.
+type plantSpot struct {
+	CircleMeta
+	area SoilArea
+}
+
+// Occupancy works out how much room is left in the soil given the living plants already in it.
+// Spots are picked greedily from the edge of the soil inwards so PlantCapacity is a close estimate rather than the most that could ever fit.
+func (s *Soil) Occupancy(plants []CircleMeta) SoilOccupancy {
+	candidates := s.plantSpotCandidates()
+
+	capacity := len(pickPlantSpots(candidates, nil))
+	freeSpots := pickPlantSpots(candidates, plants)
+
+	freeAreas := make([]SoilArea, 0)
+	for _, area := range soilAreas {
+		if slices.ContainsFunc(freeSpots, func(spot plantSpot) bool { return spot.area == area }) {
+			freeAreas = append(freeAreas, area)
+		}
+	}
+
+	return SoilOccupancy{
+		PlantCount:    len(plants),
+		PlantCapacity: max(capacity, len(plants)+len(freeSpots)),
+		FreeSpotCount: len(freeSpots),
+		FreeAreas:     freeAreas,
+	}
+}
+
+// plantSpotCandidates are the points on a grid over the soil where a plant would be fully inside it, furthest from the centre first.
+func (s *Soil) plantSpotCandidates() []plantSpot {
+	reachM := s.RadiusM() - PlantInteractionRadius
+	if reachM < 0 {
+		return nil
+	}
+
+	candidates := make([]plantSpot, 0)
+	steps := int(reachM / soilOccupancyGridStepM)
+	for i := -steps; i <= steps; i++ {
+		for j := -steps; j <= steps; j++ {
+			eastM, northM := float64(i)*soilOccupancyGridStepM, float64(j)*soilOccupancyGridStepM
+			spot := NewCircleMeta(s.Centre().OffsetM(eastM, northM), PlantInteractionRadius)
+			if s.ContainsFullCircle(NewCircleMeta(spot.Centre(), PlantInteractionRadius+soilOccupancyMarginM)) {
+				candidates = append(candidates, plantSpot{CircleMeta: spot, area: s.areaAt(eastM, northM)})
+			}
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b plantSpot) int {
+		return cmp.Compare(b.Centre().DistanceM(s.Centre()), a.Centre().DistanceM(s.Centre()))
+	})
+
+	return candidates
+}
+
+// areaAt is the area of the soil the point eastM metres east and northM metres north of its centre is in.
+func (s *Soil) areaAt(eastM, northM float64) SoilArea {
+	switch {
+	case math.Hypot(eastM, northM) <= s.RadiusM()*soilCentreAreaFraction:
+		return SoilAreaCentre
+	case eastM >= 0 && northM >= 0:
+		return SoilAreaNorthEast
+	case eastM >= 0:
+		return SoilAreaSouthEast
+	case northM < 0:
+		return SoilAreaSouthWest
+	default:
+		return SoilAreaNorthWest
+	}
+}
+
+// pickPlantSpots picks the candidates far enough from the plants and from each other to all be planted.
+func pickPlantSpots(candidates []plantSpot, plants []CircleMeta) []plantSpot {
+	taken := slices.Clone(plants)
+	picked := make([]plantSpot, 0)
+	for _, candidate := range candidates {
+		free := true
+		for _, other := range taken {
+			if candidate.Centre().DistanceM(other.Centre()) <= PlantSpacingM+soilOccupancyMarginM {
+				free = false
+				break
+			}
+		}
+
+		if free {
+			picked = append(picked, candidate)
+			taken = append(taken, candidate.CircleMeta)
+		}
+	}
+	return picked
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoilOccupancy(t *testing.T) {
+	centre := Coordinates{Lat: 51.5007, Lon: -0.1246}
+
+	freeSpots := func(soil *Soil, plants []CircleMeta) []CircleMeta {
+		spots := make([]CircleMeta, 0)
+		for _, spot := range pickPlantSpots(soil.plantSpotCandidates(), plants) {
+			spots = append(spots, spot.CircleMeta)
+		}
+		return spots
+	}
+
+	t.Run("an empty soil has room for several plants", func(t *testing.T) {
+		for _, soil := range []*Soil{
+			NewSmallSizedSoil(DefaultSoilMetaLoam, centre),
+			NewMediumSizedSoil(DefaultSoilMetaLoam, centre),
+			NewLargeSizedSoil(DefaultSoilMetaLoam, centre),
+		} {
+			occupancy := soil.Occupancy(nil)
+			assert.Equal(t, 0, occupancy.PlantCount)
+			assert.GreaterOrEqual(t, occupancy.PlantCapacity, 2)
+			assert.Equal(t, occupancy.PlantCapacity, occupancy.FreeSpotCount)
+			assert.NotEmpty(t, occupancy.FreeAreas)
+		}
+	})
+
+	t.Run("free spots can all be planted together", func(t *testing.T) {
+		soil := NewLargeSizedSoil(DefaultSoilMetaLoam, centre)
+		spots := freeSpots(soil, nil)
+
+		for i, spot := range spots {
+			assert.True(t, soil.ContainsFullCircle(spot), "expected the spot to be fully in the soil")
+			for _, other := range spots[i+1:] {
+				assert.Greater(t, spot.Centre().DistanceM(other.Centre()), PlantSpacingM)
+			}
+		}
+	})
+
+	t.Run("free spots keep clear of the plants already there", func(t *testing.T) {
+		soil := NewLargeSizedSoil(DefaultSoilMetaLoam, centre)
+		plants := []CircleMeta{NewCircleMeta(centre, PlantInteractionRadius)}
+
+		occupancy := soil.Occupancy(plants)
+		assert.Equal(t, 1, occupancy.PlantCount)
+		assert.Less(t, occupancy.FreeSpotCount, soil.Occupancy(nil).PlantCapacity)
+		assert.NotContains(t, occupancy.FreeAreas, SoilAreaCentre)
+		for _, spot := range freeSpots(soil, plants) {
+			assert.Greater(t, spot.Centre().DistanceM(centre), PlantSpacingM)
+		}
+	})
+
+	t.Run("a full soil has no free areas", func(t *testing.T) {
+		soil := NewSmallSizedSoil(DefaultSoilMetaLoam, centre)
+		full := freeSpots(soil, nil)
+
+		occupancy := soil.Occupancy(full)
+		assert.Zero(t, occupancy.FreeSpotCount)
+		assert.Empty(t, occupancy.FreeAreas)
+		assert.Equal(t, len(full), occupancy.PlantCapacity)
+	})
+
+	t.Run("areas split the soil around its centre", func(t *testing.T) {
+		soil := NewLargeSizedSoil(DefaultSoilMetaLoam, centre)
+		assert.Equal(t, SoilAreaCentre, soil.areaAt(1, -1))
+		assert.Equal(t, SoilAreaNorthEast, soil.areaAt(20, 20))
+		assert.Equal(t, SoilAreaSouthEast, soil.areaAt(20, -20))
+		assert.Equal(t, SoilAreaSouthWest, soil.areaAt(-20, -20))
+		assert.Equal(t, SoilAreaNorthWest, soil.areaAt(-20, 20))
+	})
+}
@@ -87,3 +87,31 @@ func TestContainsFullCircle(t *testing.T) {
 		assert.True(t, soil.ContainsFullCircle(circle))
 	})
 }
+
+func TestSoilEffects(t *testing.T) {
+	t.Run("default loam soil has no effect", func(t *testing.T) {
+		assert.Equal(t, NeutralSoilEffects, DefaultSoilMetaLoam.Effects())
+	})
+
+	t.Run("water retentive soil stretches grace period and slows decay", func(t *testing.T) {
+		effects := DefaultSoilMetaClay.Effects()
+
+		assert.Greater(t, effects.GracePeriodMultiplier, 1.0)
+		assert.Less(t, effects.DecayMultiplier, 1.0)
+	})
+
+	t.Run("dry soil shortens grace period and speeds up decay", func(t *testing.T) {
+		effects := DefaultSoilMetaSandy.Effects()
+
+		assert.Less(t, effects.GracePeriodMultiplier, 1.0)
+		assert.Greater(t, effects.DecayMultiplier, 1.0)
+	})
+
+	t.Run("nutrient richness scales xp", func(t *testing.T) {
+		rich := SoilMeta{Type: SoilTypeLoam, WaterRetention: 0.55, NutrientRichness: 1.0}
+		poor := SoilMeta{Type: SoilTypeLoam, WaterRetention: 0.55, NutrientRichness: 0.05}
+
+		assert.Equal(t, 1.2, rich.Effects().XpMultiplier)
+		assert.Equal(t, 0.44, poor.Effects().XpMultiplier)
+	})
+}
@@ -1,12 +1,22 @@
 package models
 
-import "math/rand/v2"
+import (
+	"math"
+	"math/rand/v2"
+)
 
 const (
+	temperMinVal = 1
 	temperMaxVal = 5
+
+	temperXpScale           = 0.05 // XP multiplier change per point of frolic over woe
+	dreadDecayScale         = 0.05 // decay multiplier change per point of dread above the minimum
+	maliceDamagePerInterval = 0.05 // HP lost by a plant every decay interval per point of a neighbour's malice above the minimum
+
+	MaliceReachM = 3 * PlantInteractionRadius // distance from a plant's centre within which its malice reaches other plants
 )
 
-// Range: [0, 5]
+// Range: [1, 5]
 type Tempers struct {
 	Woe    float64 `json:"woe"`    // -ve effect on Plant.XP
 	Frolic float64 `json:"frolic"` // +ve effect on Plant.XP
@@ -22,3 +32,44 @@ func NewTempers() *Tempers {
 		Malice: float64(rand.Int64N(temperMaxVal)) + 1.0,
 	}
 }
+
+// XpMultiplier scales XP gained by a plant. Frolic pushes it above 1 and woe pulls it below.
+// A plant without tempers is unaffected.
+func (t *Tempers) XpMultiplier() float64 {
+	if t == nil {
+		return 1.0
+	}
+	return roundMultiplier(1 + (t.Frolic-t.Woe)*temperXpScale)
+}
+
+// DecayMultiplier scales the HP a plant loses to neglect. Dread above the minimum makes it lose more.
+func (t *Tempers) DecayMultiplier() float64 {
+	if t == nil {
+		return 1.0
+	}
+	return roundMultiplier(1 + (t.Dread-temperMinVal)*dreadDecayScale)
+}
+
+// MaliceDamage is the HP this plant's malice costs each nearby plant every decay interval.
+func (t *Tempers) MaliceDamage() float64 {
+	if t == nil {
+		return 0.0
+	}
+	return (t.Malice - temperMinVal) * maliceDamagePerInterval
+}
+
+// change shifts each temper by the given amount, keeping them within their range.
+// A plant without tempers is unaffected.
+func (t *Tempers) change(woe, frolic, dread, malice float64) {
+	if t == nil {
+		return
+	}
+	t.Woe = clampTemper(t.Woe + woe)
+	t.Frolic = clampTemper(t.Frolic + frolic)
+	t.Dread = clampTemper(t.Dread + dread)
+	t.Malice = clampTemper(t.Malice + malice)
+}
+
+func clampTemper(v float64) float64 {
+	return math.Max(temperMinVal, math.Min(temperMaxVal, v))
+}
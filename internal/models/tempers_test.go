@@ -0,0 +1,181 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemperMultipliers(t *testing.T) {
+	tests := []struct {
+		name            string
+		tempers         *Tempers
+		xpMultiplier    float64
+		decayMultiplier float64
+		maliceDamage    float64
+	}{
+		{
+			name:            "plant without tempers is unaffected",
+			tempers:         nil,
+			xpMultiplier:    1.0,
+			decayMultiplier: 1.0,
+			maliceDamage:    0.0,
+		},
+		{
+			name:            "calmest tempers are unaffected",
+			tempers:         &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 1},
+			xpMultiplier:    1.0,
+			decayMultiplier: 1.0,
+			maliceDamage:    0.0,
+		},
+		{
+			name:            "frolic outweighing woe increases xp",
+			tempers:         &Tempers{Woe: 1, Frolic: 5, Dread: 1, Malice: 1},
+			xpMultiplier:    1.2,
+			decayMultiplier: 1.0,
+			maliceDamage:    0.0,
+		},
+		{
+			name:            "woe outweighing frolic decreases xp",
+			tempers:         &Tempers{Woe: 5, Frolic: 1, Dread: 1, Malice: 1},
+			xpMultiplier:    0.8,
+			decayMultiplier: 1.0,
+			maliceDamage:    0.0,
+		},
+		{
+			name:            "dread increases decay",
+			tempers:         &Tempers{Woe: 3, Frolic: 3, Dread: 5, Malice: 1},
+			xpMultiplier:    1.0,
+			decayMultiplier: 1.2,
+			maliceDamage:    0.0,
+		},
+		{
+			name:            "malice damages neighbours",
+			tempers:         &Tempers{Woe: 3, Frolic: 3, Dread: 1, Malice: 5},
+			xpMultiplier:    1.0,
+			decayMultiplier: 1.0,
+			maliceDamage:    0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.xpMultiplier, tt.tempers.XpMultiplier())
+			assert.Equal(t, tt.decayMultiplier, tt.tempers.DecayMultiplier())
+			assert.InDelta(t, tt.maliceDamage, tt.tempers.MaliceDamage(), 1e-9)
+		})
+	}
+}
+
+func TestTempersInPlantLifecycle(t *testing.T) {
+	baseTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		tempers    *Tempers
+		neighbours []*Plant
+		elapsed    time.Duration
+		expectedHp float64
+	}{
+		{
+			name:       "neutral plant loses 1 HP per interval",
+			tempers:    &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 1},
+			elapsed:    24 * time.Hour,
+			expectedHp: 94.0,
+		},
+		{
+			name:       "dreadful plant loses more HP per interval",
+			tempers:    &Tempers{Woe: 1, Frolic: 1, Dread: 5, Malice: 1},
+			elapsed:    24 * time.Hour,
+			expectedHp: 92.8,
+		},
+		{
+			name:    "malicious neighbour hurts plant",
+			tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 1},
+			neighbours: []*Plant{
+				{ID: "neighbour-1", Tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 5}},
+			},
+			elapsed:    24 * time.Hour,
+			expectedHp: 92.8,
+		},
+		{
+			name:    "malice from several neighbours stacks",
+			tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 1},
+			neighbours: []*Plant{
+				{ID: "neighbour-1", Tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 5}},
+				{ID: "neighbour-2", Tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 3}},
+			},
+			elapsed:    24 * time.Hour,
+			expectedHp: 92.2,
+		},
+		{
+			name:    "dead neighbours and the plant itself are ignored",
+			tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 5},
+			neighbours: []*Plant{
+				{ID: "plant", Tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 5}},
+				{ID: "neighbour-1", Dead: true, Tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 5}},
+			},
+			elapsed:    24 * time.Hour,
+			expectedHp: 94.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plant := &Plant{
+				ID:          "plant",
+				Hp:          100.0,
+				Tempers:     tt.tempers,
+				TimePlanted: baseTime,
+			}
+
+			plant.Refresh(baseTime.Add(tt.elapsed), tt.neighbours...)
+
+			assert.InDelta(t, tt.expectedHp, plant.Hp, 1e-9)
+		})
+	}
+
+	t.Run("malice is not held off by the grace period", func(t *testing.T) {
+		gracePeriodEnd := baseTime.Add(24 * time.Hour)
+		plant := &Plant{
+			ID:                "plant",
+			Hp:                100.0,
+			TimePlanted:       baseTime,
+			GracePeriodEndsAt: &gracePeriodEnd,
+		}
+		neighbour := &Plant{ID: "neighbour-1", Tempers: &Tempers{Woe: 1, Frolic: 1, Dread: 1, Malice: 5}}
+
+		plant.Refresh(baseTime.Add(8*time.Hour), neighbour)
+
+		assert.InDelta(t, 99.6, plant.Hp, 1e-9)
+	})
+
+	t.Run("frolic and woe scale watering xp", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			tempers    *Tempers
+			expectedXp int64
+		}{
+			{name: "frolicsome", tempers: &Tempers{Woe: 1, Frolic: 5, Dread: 1, Malice: 1}, expectedXp: 36},
+			{name: "balanced", tempers: &Tempers{Woe: 3, Frolic: 3, Dread: 1, Malice: 1}, expectedXp: 30},
+			{name: "woeful", tempers: &Tempers{Woe: 5, Frolic: 1, Dread: 1, Malice: 1}, expectedXp: 24},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				plant := &Plant{
+					Hp:          50.0,
+					Tempers:     tt.tempers,
+					LevelMeta:   NewLeveLMeta(1, 0),
+					TimePlanted: baseTime,
+				}
+
+				_, err := plant.Action(PlantActionWater, baseTime.Add(1*time.Hour))
+
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedXp, plant.XP)
+			})
+		}
+	})
+}
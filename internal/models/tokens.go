@@ -10,14 +10,21 @@ type TokenPair struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+// RefreshToken is one of the refresh tokens issued to a user.
+// Every token rotated from the same login belongs to the same family, and ParentID is the token it replaced.
+// SessionCreatedAt is when that login happened and Client is whoever the token was issued to.
 type RefreshToken struct {
-	ID        string
-	UserID    string
-	Hash      []byte
-	Plain     string
-	CreatedAt time.Time
-	ExpiresAt time.Time
-	RevokedAt *time.Time
+	ID               string
+	UserID           string
+	FamilyID         string
+	ParentID         *string
+	Hash             []byte
+	Plain            string
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	SessionCreatedAt time.Time
+	Client           ClientInfo
 }
 
 var (
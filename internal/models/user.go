@@ -7,22 +7,28 @@ import (
 )
 
 type User struct {
-	ID           string    `json:"id"`
-	Username     string    `json:"username"`
-	Title        string    `json:"title"`
-	Email        string    `json:"email"`
-	PasswordHash []byte    `json:"-"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	Title         string    `json:"title"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	Role          Role      `json:"role"`
+	PasswordHash  []byte    `json:"-"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	TokenVersion  int64     `json:"-"` // bumped to invalidate every access token issued before
+	// set while the user has asked for their account to be deleted and can still change their mind by logging in
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
 	LevelMeta
 }
 
 type UserProfile struct {
-	Username        string   `json:"username"`
-	Title           string   `json:"title"`
-	Level           int64    `json:"level"`
-	Top3AlivePlants []*Plant `json:"top3AlivePlants"`
-	DeceasedPlants  []*Plant `json:"deceasedPlants"`
+	Username        string         `json:"username"`
+	Title           string         `json:"title"`
+	Level           int64          `json:"level"`
+	Top3AlivePlants []*Plant       `json:"top3AlivePlants"`
+	DeceasedPlants  []*Plant       `json:"deceasedPlants"`
+	Achievements    []*Achievement `json:"achievements"`
 	PlantCount      `json:"plantCount"`
 	SeedCount       `json:"seedCount"`
 }
@@ -41,7 +47,26 @@ var (
 	ErrUserNotFound = errors.New("user not found")
 )
 
-func NewUserProfile(user *User, plantCount *PlantCount, seedCount *SeedCount, plants []*Plant) *UserProfile {
+func (u *User) GainXp(xp int64) *PlayerProgress {
+	previousLevel := u.Level
+	u.addXp(xp)
+
+	progress := &PlayerProgress{
+		XpGained:  xp,
+		LevelMeta: u.LevelMeta,
+	}
+
+	if u.Level > previousLevel {
+		progress.LevelUp = &LevelUpEvent{
+			PreviousLevel: previousLevel,
+			NewLevel:      u.Level,
+		}
+	}
+
+	return progress
+}
+
+func NewUserProfile(user *User, plantCount *PlantCount, seedCount *SeedCount, plants []*Plant, achievements []*Achievement) *UserProfile {
 	userProfile := new(UserProfile)
 
 	userProfile.Username = user.Username
@@ -50,6 +75,7 @@ func NewUserProfile(user *User, plantCount *PlantCount, seedCount *SeedCount, pl
 
 	userProfile.PlantCount = *plantCount
 	userProfile.SeedCount = *seedCount
+	userProfile.Achievements = achievements
 
 	userProfile.Top3AlivePlants = make([]*Plant, 0)
 	userProfile.DeceasedPlants = make([]*Plant, 0)
@@ -59,6 +85,9 @@ func NewUserProfile(user *User, plantCount *PlantCount, seedCount *SeedCount, pl
 
 	for _, plant := range plants {
 		plant.CircleMeta = CircleMeta{}
+		if plant.Soil != nil {
+			plant.Soil.CircleMeta = CircleMeta{}
+		}
 
 		if plant.Dead {
 			deceasedPlants = append(deceasedPlants, plant)
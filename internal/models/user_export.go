@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UserDataExport is everything stored about a user, for them to take away.
+type UserDataExport struct {
+	ExportedAt   time.Time      `json:"exportedAt"`
+	User         *User          `json:"user"`
+	Seeds        []*Seed        `json:"seeds"`
+	Plants       []*Plant       `json:"plants"`
+	SeedRequests []*SeedRequest `json:"seedRequests"`
+	Sessions     []*Session     `json:"sessions"`
+	Identities   []*Identity    `json:"identities"`
+}
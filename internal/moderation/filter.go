@@ -0,0 +1,41 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrContentRejected = errors.New("content rejected")
+
+// Field is the kind of user supplied text being checked.
+type Field string
+
+const (
+	FieldUsername Field = "username"
+	FieldNickname Field = "nickname"
+)
+
+const (
+	ReasonBlockedTerm    = "blocked_term"
+	ReasonBlockedPattern = "blocked_pattern"
+)
+
+// ContentFilter decides whether text users pick for themselves can be shown to other players.
+type ContentFilter interface {
+	// Check returns a *RejectedError if the text is not allowed
+	Check(field Field, text string) error
+}
+
+// RejectedError says which field was rejected and why, without repeating what matched so the filter can't be probed term by term.
+type RejectedError struct {
+	Field  Field
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s contains content that is not allowed", e.Field)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return ErrContentRejected
+}
@@ -0,0 +1,115 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables are letters from other scripts that look like latin ones. NFKD already
+// takes care of accents and compatibility forms like fullwidth and mathematical letters.
+var confusables = map[rune]rune{
+	// cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p',
+	'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ї': 'i', 'ј': 'j', 'ԁ': 'd',
+	'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l', 'ь': 'b',
+	// greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// latin lookalikes
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'đ': 'd', 'ħ': 'h', 'ß': 's', 'æ': 'a', 'œ': 'o',
+}
+
+var leetspeak = map[rune]rune{
+	'0': 'o', '1': 'i', '2': 'z', '3': 'e', '4': 'a', '5': 's', '6': 'g', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '!': 'i', '|': 'l', '+': 't', '€': 'e', '£': 'l',
+}
+
+// Normalise lowercases text and maps accented letters, lookalikes from other scripts and leetspeak to plain latin letters,
+// so "ＦＵＣＫ", "fúck" and "f4ck" are all compared as if they were written plainly.
+func Normalise(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(normaliseRune(r))
+	}
+	return b.String()
+}
+
+func normaliseRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if plain, ok := confusables[r]; ok {
+		r = plain
+	}
+	if plain, ok := leetspeak[r]; ok {
+		r = plain
+	}
+	return r
+}
+
+// words splits text into its normalised words. A word ends at anything that isn't a letter and where a capital
+// follows a small letter, so "SoBad" is "so" and "bad". Runs of single letters are joined back up so "b a d" is still "bad".
+func words(text string) []string {
+	split := make([]string, 0)
+	var word strings.Builder
+	endWord := func() {
+		if word.Len() > 0 {
+			split = append(split, word.String())
+			word.Reset()
+		}
+	}
+
+	previousLower := false
+	for _, r := range norm.NFKD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		plain := normaliseRune(r)
+		if !unicode.IsLetter(plain) {
+			endWord()
+			previousLower = false
+			continue
+		}
+
+		if previousLower && unicode.IsUpper(r) {
+			endWord()
+		}
+		word.WriteRune(plain)
+		previousLower = unicode.IsLower(r)
+	}
+	endWord()
+
+	joined := make([]string, 0, len(split))
+	singles := ""
+	for _, w := range split {
+		if utf8.RuneCountInString(w) == 1 {
+			singles += w
+			continue
+		}
+		if singles != "" {
+			joined = append(joined, singles)
+			singles = ""
+		}
+		joined = append(joined, w)
+	}
+	if singles != "" {
+		joined = append(joined, singles)
+	}
+
+	return joined
+}
+
+// lettersOnly drops everything but letters, so "b.a.d" and "b a d" are compared as "bad".
+func lettersOnly(normalised string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) {
+			return -1
+		}
+		return r
+	}, normalised)
+}
@@ -0,0 +1,220 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//go:embed default_wordlist.txt
+var defaultWordlist string
+
+// rules are compiled from a wordlist. Blocked words are matched against each word of the text, with an optional
+// plural s, while words blocked anywhere and allowed words are matched against the text with everything but letters
+// removed. Each of their letters may be repeated so "baaad" is caught by "bad".
+type rules struct {
+	blocked         *regexp.Regexp
+	blockedAnywhere *regexp.Regexp
+	allowed         *regexp.Regexp
+	patterns        []*regexp.Regexp
+}
+
+// WordlistFilter is a ContentFilter built from a list of words and regular expressions. When it is loaded from a file
+// it can be reloaded while it is in use, so the list can be changed without restarting.
+type WordlistFilter struct {
+	path    string
+	rules   atomic.Pointer[rules]
+	modTime time.Time
+	size    int64
+}
+
+// NewWordlistFilter parses a wordlist in the format of default_wordlist.txt.
+func NewWordlistFilter(wordlist io.Reader) (*WordlistFilter, error) {
+	r, err := parseRules(wordlist)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &WordlistFilter{}
+	f.rules.Store(r)
+	return f, nil
+}
+
+// NewDefaultFilter uses the wordlist built into the app.
+func NewDefaultFilter() *WordlistFilter {
+	f, err := NewWordlistFilter(strings.NewReader(defaultWordlist))
+	if err != nil {
+		panic(fmt.Sprintf("moderation: invalid default wordlist: %v", err))
+	}
+	return f
+}
+
+// LoadWordlistFile loads the wordlist from a file that can later be reloaded with Reload or Watch.
+func LoadWordlistFile(path string) (*WordlistFilter, error) {
+	f := &WordlistFilter{path: path}
+	if _, err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *WordlistFilter) Check(field Field, text string) error {
+	r := f.rules.Load()
+
+	normalised := Normalise(text)
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(normalised) {
+			return &RejectedError{Field: field, Reason: ReasonBlockedPattern}
+		}
+	}
+
+	if r.blockedAnywhere != nil {
+		letters := lettersOnly(normalised)
+		if r.allowed != nil {
+			// a non letter is left in place of allowed words so they can't join up with the letters around them
+			letters = r.allowed.ReplaceAllString(letters, " ")
+		}
+		if r.blockedAnywhere.MatchString(letters) {
+			return &RejectedError{Field: field, Reason: ReasonBlockedTerm}
+		}
+	}
+
+	if r.blocked != nil {
+		for _, word := range words(text) {
+			if r.blocked.MatchString(word) {
+				return &RejectedError{Field: field, Reason: ReasonBlockedTerm}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reload reads the file again if it has changed since it was last read and reports whether it did.
+// If the file can't be read or parsed the rules already loaded are kept.
+func (f *WordlistFilter) Reload() (bool, error) {
+	if f.path == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+
+	if f.rules.Load() != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return false, nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return false, err
+	}
+	//nolint:errcheck
+	defer file.Close()
+
+	r, err := parseRules(file)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	f.rules.Store(r)
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return true, nil
+}
+
+// Watch reloads the file whenever it changes until ctx is cancelled. Errors are passed to onError
+// and the rules already loaded stay in use until the file is fixed.
+func (f *WordlistFilter) Watch(ctx context.Context, interval time.Duration, onReload func(), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := f.Reload()
+			switch {
+			case err != nil:
+				onError(err)
+			case reloaded:
+				onReload()
+			}
+		}
+	}
+}
+
+func parseRules(wordlist io.Reader) (*rules, error) {
+	var blocked, blockedAnywhere, allowed []string
+	var patterns []*regexp.Regexp
+
+	scanner := bufio.NewScanner(wordlist)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "re:"):
+			pattern, err := regexp.Compile("(?i)" + strings.TrimPrefix(line, "re:"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			patterns = append(patterns, pattern)
+		case strings.HasPrefix(line, "*"):
+			if word := wordPattern(strings.TrimPrefix(line, "*")); word != "" {
+				blockedAnywhere = append(blockedAnywhere, word)
+			}
+		case strings.HasPrefix(line, "!"):
+			if word := wordPattern(strings.TrimPrefix(line, "!")); word != "" {
+				allowed = append(allowed, word)
+			}
+		default:
+			if word := wordPattern(line); word != "" {
+				blocked = append(blocked, word)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	r := &rules{
+		blockedAnywhere: compileAlternation(blockedAnywhere),
+		allowed:         compileAlternation(allowed),
+		patterns:        patterns,
+	}
+	if len(blocked) > 0 {
+		r.blocked = regexp.MustCompile("^(?:" + strings.Join(blocked, "|") + ")s?$")
+	}
+	return r, nil
+}
+
+// wordPattern matches the word however many times each of its letters is repeated
+func wordPattern(word string) string {
+	var b strings.Builder
+	for _, r := range lettersOnly(Normalise(word)) {
+		b.WriteString(regexp.QuoteMeta(string(r)))
+		b.WriteString("+")
+	}
+	return b.String()
+}
+
+func compileAlternation(words []string) *regexp.Regexp {
+	if len(words) == 0 {
+		return nil
+	}
+	return regexp.MustCompile("(?:" + strings.Join(words, "|") + ")")
+}
@@ -0,0 +1,114 @@
+package moderation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalise(t *testing.T) {
+	assert.Equal(t, "fuck", Normalise("ＦＵＣＫ"))
+	assert.Equal(t, "fuck", Normalise("fúçk"))
+	assert.Equal(t, "fuck", Normalise("fυсk"), "greek upsilon and cyrillic es")
+	assert.Equal(t, "shit", Normalise("5h1t"))
+}
+
+func TestWordlistFilter(t *testing.T) {
+	f, err := NewWordlistFilter(strings.NewReader("# comment\nbad\n*vile\n!servile\nre:^admin\n"))
+	assert.NoError(t, err)
+
+	t.Run("blocked words are found however they are written", func(t *testing.T) {
+		for _, text := range []string{"bad", "bads", "so bad", "SoBad", "b4d", "b.a.d", "b a d", "baaaad", "ｂａｄ", "bád", "bаd"} {
+			err := f.Check(FieldUsername, text)
+
+			var rejected *RejectedError
+			if assert.True(t, errors.As(err, &rejected), text) {
+				assert.Equal(t, FieldUsername, rejected.Field)
+				assert.Equal(t, ReasonBlockedTerm, rejected.Reason)
+			}
+			assert.ErrorIs(t, err, ErrContentRejected)
+		}
+	})
+
+	t.Run("blocked words are only matched as whole words", func(t *testing.T) {
+		for _, text := range []string{"badminton", "Abadan", "bead", "ba d", "Ba Dumtss"} {
+			assert.NoError(t, f.Check(FieldNickname, text), text)
+		}
+	})
+
+	t.Run("words blocked anywhere are found inside other words", func(t *testing.T) {
+		assert.Error(t, f.Check(FieldNickname, "reviled"))
+		assert.Error(t, f.Check(FieldNickname, "vi le"))
+		assert.NoError(t, f.Check(FieldNickname, "servile"))
+		assert.Error(t, f.Check(FieldNickname, "servilevile"))
+	})
+
+	t.Run("patterns are matched against the normalised text", func(t *testing.T) {
+		var rejected *RejectedError
+		assert.True(t, errors.As(f.Check(FieldUsername, "Admin"), &rejected))
+		assert.Equal(t, ReasonBlockedPattern, rejected.Reason)
+		assert.NoError(t, f.Check(FieldUsername, "notadmin"))
+	})
+
+	t.Run("invalid patterns are rejected", func(t *testing.T) {
+		_, err := NewWordlistFilter(strings.NewReader("re:(\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestDefaultFilter(t *testing.T) {
+	f := NewDefaultFilter()
+
+	assert.Error(t, f.Check(FieldUsername, "fuuuck"))
+	assert.Error(t, f.Check(FieldUsername, "motherfucker"))
+	assert.Error(t, f.Check(FieldNickname, "sh1t head"))
+	assert.Error(t, f.Check(FieldNickname, "Big Twat"))
+	assert.NoError(t, f.Check(FieldNickname, "Peacock"))
+	assert.NoError(t, f.Check(FieldNickname, "Grape"))
+	assert.NoError(t, f.Check(FieldUsername, "scunthorpe"))
+	assert.NoError(t, f.Check(FieldUsername, "classic"))
+	assert.NoError(t, f.Check(FieldUsername, "assistant"))
+
+	t.Run("blocked words inside innocent words are allowed", func(t *testing.T) {
+		for _, text := range []string{"Rapeseed", "Cocktail", "Skyscraper", "Cocklebur", "Matsushita", "Scott Wat", "therapist"} {
+			assert.NoError(t, f.Check(FieldNickname, text), text)
+		}
+	})
+}
+
+func TestWordlistFilterReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wordlist.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("bad\n"), 0o644))
+
+	f, err := LoadWordlistFile(path)
+	assert.NoError(t, err)
+	assert.Error(t, f.Check(FieldUsername, "bad"))
+	assert.NoError(t, f.Check(FieldUsername, "worse"))
+
+	reloaded, err := f.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded, "the file hasn't changed")
+
+	assert.NoError(t, os.WriteFile(path, []byte("worse\n"), 0o644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	reloaded, err = f.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.NoError(t, f.Check(FieldUsername, "bad"))
+	assert.Error(t, f.Check(FieldUsername, "worse"))
+
+	t.Run("a broken file keeps the rules already loaded", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte("re:(\n"), 0o644))
+		assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+
+		_, err := f.Reload()
+		assert.Error(t, err)
+		assert.Error(t, f.Check(FieldUsername, "worse"))
+	})
+}
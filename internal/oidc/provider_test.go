@@ -0,0 +1,202 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockIssuer is a minimal OIDC provider. It hands out an ID token with idTokenClaims for the code "good-code"
+// as long as the code verifier matches the challenge the authorisation URL was made with.
+type mockIssuer struct {
+	*httptest.Server
+	key           *rsa.PrivateKey
+	codeChallenge string
+	idTokenClaims jwt.MapClaims
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockIssuer{key: key}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock-key",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "moota" || clientSecret != "shh" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+
+		if r.PostFormValue("code") != "good-code" || CodeChallenge(r.PostFormValue("code_verifier")) != m.codeChallenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.idTokenClaims)
+		token.Header["kid"] = "mock-key"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		//nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+func TestProvider(t *testing.T) {
+	issuer := newMockIssuer(t)
+	provider := NewProvider(Config{
+		Name:         "mock",
+		Issuer:       issuer.URL,
+		ClientID:     "moota",
+		ClientSecret: "shh",
+		RedirectURL:  "http://localhost/api/auth/oidc/mock/callback",
+	})
+
+	ctx := context.Background()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                issuer.URL,
+			"sub":                "subject-1",
+			"aud":                "moota",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"iat":                time.Now().Unix(),
+			"nonce":              "the-nonce",
+			"email":              "test@example.com",
+			"email_verified":     true,
+			"preferred_username": "tester",
+		}
+	}
+
+	codeVerifier, err := RandomString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer.codeChallenge = CodeChallenge(codeVerifier)
+
+	t.Run("auth code url carries state, nonce and the pkce challenge", func(t *testing.T) {
+		authURL, err := provider.AuthCodeURL(ctx, "the-state", "the-nonce", issuer.codeChallenge, false)
+		assert.NoError(t, err)
+
+		u, err := url.Parse(authURL)
+		if assert.NoError(t, err) {
+			assert.Equal(t, issuer.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+			assert.Equal(t, "the-state", u.Query().Get("state"))
+			assert.Equal(t, "the-nonce", u.Query().Get("nonce"))
+			assert.Equal(t, issuer.codeChallenge, u.Query().Get("code_challenge"))
+			assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+		}
+	})
+
+	t.Run("auth code url asks for a fresh login when reauthenticating", func(t *testing.T) {
+		authURL, err := provider.AuthCodeURL(ctx, "the-state", "the-nonce", issuer.codeChallenge, true)
+		assert.NoError(t, err)
+
+		u, err := url.Parse(authURL)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "login", u.Query().Get("prompt"))
+			assert.Equal(t, "0", u.Query().Get("max_age"))
+		}
+	})
+
+	t.Run("exchanges the code for verified claims", func(t *testing.T) {
+		issuer.idTokenClaims = validClaims()
+
+		claims, err := provider.Exchange(ctx, "good-code", codeVerifier, "the-nonce")
+		assert.NoError(t, err)
+		assert.Equal(t, &Claims{Subject: "subject-1", Email: "test@example.com", EmailVerified: true, PreferredUsername: "tester"}, claims)
+	})
+
+	t.Run("carries the auth time when the provider sends it", func(t *testing.T) {
+		authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+		issuer.idTokenClaims = validClaims()
+		issuer.idTokenClaims["auth_time"] = authTime.Unix()
+
+		claims, err := provider.Exchange(ctx, "good-code", codeVerifier, "the-nonce")
+		assert.NoError(t, err)
+		assert.True(t, authTime.Equal(claims.AuthTime))
+	})
+
+	t.Run("rejects a wrong code verifier", func(t *testing.T) {
+		issuer.idTokenClaims = validClaims()
+
+		_, err := provider.Exchange(ctx, "good-code", "wrong-verifier", "the-nonce")
+		assert.ErrorIs(t, err, ErrExchangeFailed)
+	})
+
+	t.Run("rejects a replayed token with another nonce", func(t *testing.T) {
+		issuer.idTokenClaims = validClaims()
+
+		_, err := provider.Exchange(ctx, "good-code", codeVerifier, "another-nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects tokens meant for another client", func(t *testing.T) {
+		issuer.idTokenClaims = validClaims()
+		issuer.idTokenClaims["aud"] = "someone-else"
+
+		_, err := provider.Exchange(ctx, "good-code", codeVerifier, "the-nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects tokens from another issuer", func(t *testing.T) {
+		issuer.idTokenClaims = validClaims()
+		issuer.idTokenClaims["iss"] = "https://evil.example"
+
+		_, err := provider.Exchange(ctx, "good-code", codeVerifier, "the-nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("rejects expired tokens", func(t *testing.T) {
+		issuer.idTokenClaims = validClaims()
+		issuer.idTokenClaims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+		_, err := provider.Exchange(ctx, "good-code", codeVerifier, "the-nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
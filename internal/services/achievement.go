@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/store"
+)
+
+type AchievementService interface {
+	GetUserAchievements(context.Context, string) ([]*models.Achievement, error)
+	EquipTitle(context.Context, string, dto.EquipTitleReq) (*models.User, error)
+}
+
+type achievementService struct {
+	store *store.Store
+}
+
+func NewAchievementService(store *store.Store) AchievementService {
+	return &achievementService{
+		store: store,
+	}
+}
+
+func (s *achievementService) GetUserAchievements(ctx context.Context, userID string) ([]*models.Achievement, error) {
+	return s.store.Achievement.GetByUserID(ctx, userID)
+}
+
+func (s *achievementService) EquipTitle(ctx context.Context, userID string, dto dto.EquipTitleReq) (*models.User, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByIDForUpdate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty achievement ID unequips the current title
+	title := ""
+	if dto.AchievementID != "" {
+		meta, err := models.GetAchievementMeta(models.AchievementID(dto.AchievementID))
+		if err != nil {
+			return nil, err
+		}
+
+		earned, err := tx.Achievement.GetByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !hasAchievement(earned, meta.ID) {
+			return nil, models.ErrAchievementNotEarned
+		}
+		title = meta.Title
+	}
+
+	user.Title = title
+	if err := tx.User.UpdateTitle(ctx, user.ID, user.Title); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// evaluateAchievements records any achievements the user now qualifies for using the store it is given
+// and returns the ones that were newly earned.
+func evaluateAchievements(ctx context.Context, tx *store.Store, userID string, now time.Time) ([]*models.Achievement, error) {
+	stats, err := tx.Achievement.GetStatsByUserID(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	newAchievements := make([]*models.Achievement, 0)
+	for _, meta := range models.EarnedAchievements(*stats) {
+		achievement := &models.Achievement{
+			AchievementMeta: meta,
+			UserID:          userID,
+			EarnedAt:        now,
+		}
+
+		inserted, err := tx.Achievement.Insert(ctx, achievement)
+		if err != nil {
+			return nil, err
+		}
+
+		if inserted {
+			newAchievements = append(newAchievements, achievement)
+		}
+	}
+
+	return newAchievements, nil
+}
+
+func hasAchievement(achievements []*models.Achievement, id models.AchievementID) bool {
+	for _, achievement := range achievements {
+		if achievement.ID == id {
+			return true
+		}
+	}
+	return false
+}
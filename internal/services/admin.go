@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/store"
+)
+
+var (
+	ErrCannotChangeOwnRole = errors.New("admins cannot change their own role")
+	ErrPlantNotDead        = errors.New("plant is not dead")
+	ErrTargetHasHigherRole = errors.New("cannot act on a user with a higher role")
+)
+
+const (
+	AdminDefaultPageSize = 20
+	AdminMaxPageSize     = 100
+
+	// defaultRevivalHp is the HP a plant is revived with when the admin doesn't say
+	defaultRevivalHp = 50.0
+)
+
+// AdminService is for moderators and admins. Everything that changes something is written to the audit log
+// in the same transaction as the change, along with who made it and why.
+type AdminService interface {
+	SearchUsers(context.Context, string, int, int) ([]*models.User, bool, error)
+	SetUserRole(context.Context, string, string, dto.AdminSetRoleReq) (*models.User, error)
+	RenameUser(context.Context, string, string, dto.AdminRenameUserReq) (*models.User, error)
+	RenamePlant(context.Context, string, string, dto.AdminRenamePlantReq) (*models.Plant, error)
+	RevokeUserSessions(context.Context, string, string, dto.AdminRevokeSessionsReq) error
+	GrantSeeds(context.Context, string, string, dto.AdminGrantSeedsReq) ([]*models.Seed, error)
+	RevivePlant(context.Context, string, string, dto.AdminRevivePlantReq) (*models.Plant, error)
+	DeletePlant(context.Context, string, string, dto.AdminDeletePlantReq) error
+	GetServerStats(context.Context) (*models.ServerStats, error)
+	GetAuditLog(context.Context, int, int) ([]*models.AuditLogEntry, bool, error)
+}
+
+type adminService struct {
+	store         *store.Store
+	clock         models.Clock
+	gameClock     models.Clock // used for the plants so revived plants decay from the game's time
+	contentFilter moderation.ContentFilter
+}
+
+func NewAdminService(store *store.Store, clock, gameClock models.Clock, contentFilter moderation.ContentFilter) AdminService {
+	return &adminService{
+		store:         store,
+		clock:         clock,
+		gameClock:     gameClock,
+		contentFilter: contentFilter,
+	}
+}
+
+func (s *adminService) SearchUsers(ctx context.Context, query string, page, pageSize int) ([]*models.User, bool, error) {
+	if page < 1 || pageSize < 1 || pageSize > AdminMaxPageSize {
+		return nil, false, ErrInvalidPagination
+	}
+
+	// one extra user is fetched to tell whether there is another page
+	users, err := s.store.User.Search(ctx, query, pageSize+1, (page-1)*pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(users) > pageSize
+	if hasMore {
+		users = users[:pageSize]
+	}
+
+	return users, hasMore, nil
+}
+
+func (s *adminService) SetUserRole(ctx context.Context, actorID, userID string, dto dto.AdminSetRoleReq) (*models.User, error) {
+	role := models.Role(dto.Role)
+	if !role.Valid() {
+		return nil, models.ErrInvalidRole
+	}
+
+	// stops the last admin from locking everyone out by demoting themselves
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousRole := user.Role
+	if err := tx.User.SetRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionSetRole, models.AuditTargetUser, user.ID, map[string]any{
+		"from": previousRole,
+		"to":   role,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *adminService) RenameUser(ctx context.Context, actorID, userID string, dto dto.AdminRenameUserReq) (*models.User, error) {
+	if err := isValidUsername(dto.NewUsername); err != nil {
+		return nil, err
+	}
+
+	if err := s.contentFilter.Check(moderation.FieldUsername, dto.NewUsername); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	if _, err := tx.User.GetByUsername(ctx, dto.NewUsername); err == nil {
+		return nil, ErrUsernameTaken
+	}
+
+	user, err := tx.User.GetByIDForUpdate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkActorRank(ctx, tx, actorID, user); err != nil {
+		return nil, err
+	}
+
+	previousUsername := user.Username
+	user.Username = dto.NewUsername
+	if err := tx.User.UpdateUsername(ctx, user.ID, user.Username); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionRenameUser, models.AuditTargetUser, user.ID, map[string]any{
+		"from":   previousUsername,
+		"to":     user.Username,
+		"reason": dto.Reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *adminService) RenamePlant(ctx context.Context, actorID, plantID string, dto dto.AdminRenamePlantReq) (*models.Plant, error) {
+	if err := s.contentFilter.Check(moderation.FieldNickname, dto.NewNickname); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{IncludeDeceased: true, ForUpdate: true})
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := tx.User.GetByID(ctx, plant.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkActorRank(ctx, tx, actorID, owner); err != nil {
+		return nil, err
+	}
+
+	if err := refreshPlantData(ctx, tx, plant, s.gameClock.Now()); err != nil {
+		return nil, err
+	}
+
+	previousNickname := plant.Nickname
+	plant.Nickname = dto.NewNickname
+	if err := tx.Plant.Update(ctx, plant); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionRenamePlant, models.AuditTargetPlant, plant.ID, map[string]any{
+		"from":   previousNickname,
+		"to":     plant.Nickname,
+		"reason": dto.Reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return plant, nil
+}
+
+// RevokeUserSessions logs the user out everywhere.
+func (s *adminService) RevokeUserSessions(ctx context.Context, actorID, userID string, dto dto.AdminRevokeSessionsReq) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkActorRank(ctx, tx, actorID, user); err != nil {
+		return err
+	}
+
+	if err := revokeAllTokens(ctx, tx, user); err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionRevokeSessions, models.AuditTargetUser, user.ID, map[string]any{
+		"reason": dto.Reason,
+	}); err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+// GrantSeeds gives the user new seeds without recording a seed request, so it doesn't touch their request cooldown.
+func (s *adminService) GrantSeeds(ctx context.Context, actorID, userID string, dto dto.AdminGrantSeedsReq) ([]*models.Seed, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := make([]*models.Seed, 0, dto.Count)
+	for range dto.Count {
+		seed := models.NewSeed(user.ID)
+		if err := tx.Seed.Insert(ctx, seed); err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, seed)
+	}
+
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionGrantSeeds, models.AuditTargetUser, user.ID, map[string]any{
+		"count":  dto.Count,
+		"reason": dto.Reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return seeds, nil
+}
+
+func (s *adminService) RevivePlant(ctx context.Context, actorID, plantID string, dto dto.AdminRevivePlantReq) (*models.Plant, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{IncludeDeceased: true, ForUpdate: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if plant.Alive() {
+		return nil, ErrPlantNotDead
+	}
+
+	hp := dto.Hp
+	if hp == 0 {
+		hp = defaultRevivalHp
+	}
+
+	plant.Revive(s.gameClock.Now(), hp)
+	if err := tx.Plant.Update(ctx, plant); err != nil {
+		return nil, err
+	}
+
+	events := plant.PopEvents()
+	for _, event := range events {
+		event.ActorID = &actorID
+	}
+
+	if err := insertPlantEvents(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionRevivePlant, models.AuditTargetPlant, plant.ID, map[string]any{
+		"hp":     plant.Hp,
+		"reason": dto.Reason,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return plant, nil
+}
+
+func (s *adminService) DeletePlant(ctx context.Context, actorID, plantID string, dto dto.AdminDeletePlantReq) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{IncludeDeceased: true, ForUpdate: true})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Plant.Delete(ctx, plant.ID); err != nil {
+		return err
+	}
+
+	// the plant is gone, so what it was is kept in the audit log
+	if err := s.recordAudit(ctx, tx, actorID, models.AuditActionDeletePlant, models.AuditTargetPlant, plant.ID, map[string]any{
+		"ownerID":  plant.OwnerID,
+		"nickname": plant.Nickname,
+		"reason":   dto.Reason,
+	}); err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+func (s *adminService) GetServerStats(ctx context.Context) (*models.ServerStats, error) {
+	return s.store.Admin.GetServerStats(ctx, s.clock.Now())
+}
+
+func (s *adminService) GetAuditLog(ctx context.Context, page, pageSize int) ([]*models.AuditLogEntry, bool, error) {
+	if page < 1 || pageSize < 1 || pageSize > AdminMaxPageSize {
+		return nil, false, ErrInvalidPagination
+	}
+
+	entries, err := s.store.Admin.GetAuditLog(ctx, pageSize+1, (page-1)*pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		entries = entries[:pageSize]
+	}
+
+	return entries, hasMore, nil
+}
+
+// checkActorRank stops moderators from acting on admins, whose names, plants and sessions only other admins may touch.
+func checkActorRank(ctx context.Context, tx *store.Store, actorID string, target *models.User) error {
+	actor, err := tx.User.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+
+	if !actor.Role.AtLeast(target.Role) {
+		return ErrTargetHasHigherRole
+	}
+
+	return nil
+}
+
+func (s *adminService) recordAudit(ctx context.Context, tx *store.Store, actorID string, action models.AuditAction, targetType models.AuditTargetType, targetID string, details map[string]any) error {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	return tx.Admin.InsertAuditLogEntry(ctx, &models.AuditLogEntry{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    detailsJSON,
+		CreatedAt:  s.clock.Now(),
+	})
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminActions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping admin service integration tests")
+	}
+
+	store := newTestStore(t, "admin_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	contentFilter, err := moderation.NewWordlistFilter(strings.NewReader("rude\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminService := NewAdminService(store, clock, clock, contentFilter)
+
+	ctx := context.Background()
+	adminID := "00000000-0000-4000-a000-000000000001"
+	userID := "00000000-0000-4000-a000-000000000002"
+	plantID := "00000000-0000-4000-d000-000000000001"
+	adminPlantID := "00000000-0000-4000-d000-000000000002"
+
+	t.Run("users can be searched for", func(t *testing.T) {
+		users, hasMore, err := adminService.SearchUsers(ctx, "RUDE", 1, 10)
+		assert.NoError(t, err)
+		assert.False(t, hasMore)
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, userID, users[0].ID)
+		}
+
+		users, _, err = adminService.SearchUsers(ctx, "%", 1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, users, "expected wildcards in the query to be matched literally")
+	})
+
+	t.Run("offensive usernames can be changed", func(t *testing.T) {
+		user, err := adminService.RenameUser(ctx, adminID, userID, dto.AdminRenameUserReq{NewUsername: "renamed", Reason: "offensive"})
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", user.Username)
+
+		_, err = adminService.RenameUser(ctx, adminID, userID, dto.AdminRenameUserReq{NewUsername: "admin"})
+		assert.ErrorIs(t, err, ErrUsernameTaken)
+
+		_, err = adminService.RenameUser(ctx, adminID, userID, dto.AdminRenameUserReq{NewUsername: "rude"})
+		assert.ErrorIs(t, err, moderation.ErrContentRejected)
+	})
+
+	t.Run("plant nicknames are checked like the owner's own", func(t *testing.T) {
+		_, err := adminService.RenamePlant(ctx, adminID, plantID, dto.AdminRenamePlantReq{NewNickname: "Rude Sprout"})
+		assert.ErrorIs(t, err, moderation.ErrContentRejected)
+
+		plant, err := adminService.RenamePlant(ctx, adminID, plantID, dto.AdminRenamePlantReq{NewNickname: "Sprout"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Sprout", plant.Nickname)
+	})
+
+	t.Run("seeds can be granted during the request cooldown", func(t *testing.T) {
+		if err := store.Seed.InsertSeedRequest(ctx, userID, clock.Now(), true, 4); err != nil {
+			t.Fatal(err)
+		}
+
+		seeds, err := adminService.GrantSeeds(ctx, adminID, userID, dto.AdminGrantSeedsReq{Count: 3})
+		assert.NoError(t, err)
+		assert.Len(t, seeds, 3)
+
+		lastRequest, err := store.Seed.GetLastFulfilledSeedRequestTimeByUserID(ctx, userID)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, clock.Now(), lastRequest, time.Second, "expected the cooldown to be left alone")
+	})
+
+	t.Run("dead plants can be revived", func(t *testing.T) {
+		plant, err := adminService.RevivePlant(ctx, adminID, plantID, dto.AdminRevivePlantReq{})
+		assert.NoError(t, err)
+		assert.True(t, plant.Alive())
+		assert.Equal(t, defaultRevivalHp, plant.Hp)
+
+		_, err = adminService.RevivePlant(ctx, adminID, plantID, dto.AdminRevivePlantReq{})
+		assert.ErrorIs(t, err, ErrPlantNotDead)
+	})
+
+	t.Run("admins can't change their own role", func(t *testing.T) {
+		_, err := adminService.SetUserRole(ctx, adminID, adminID, dto.AdminSetRoleReq{Role: "user"})
+		assert.ErrorIs(t, err, ErrCannotChangeOwnRole)
+
+		user, err := adminService.SetUserRole(ctx, adminID, userID, dto.AdminSetRoleReq{Role: "moderator"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleModerator, user.Role)
+	})
+
+	t.Run("moderators can't act on admins", func(t *testing.T) {
+		_, err := adminService.RenameUser(ctx, userID, adminID, dto.AdminRenameUserReq{NewUsername: "demoted"})
+		assert.ErrorIs(t, err, ErrTargetHasHigherRole)
+
+		err = adminService.RevokeUserSessions(ctx, userID, adminID, dto.AdminRevokeSessionsReq{})
+		assert.ErrorIs(t, err, ErrTargetHasHigherRole)
+
+		_, err = adminService.RenamePlant(ctx, userID, adminPlantID, dto.AdminRenamePlantReq{NewNickname: "Demoted Sprout"})
+		assert.ErrorIs(t, err, ErrTargetHasHigherRole)
+	})
+
+	t.Run("plants can be deleted", func(t *testing.T) {
+		err := adminService.DeletePlant(ctx, adminID, plantID, dto.AdminDeletePlantReq{Reason: "offensive"})
+		assert.NoError(t, err)
+
+		err = adminService.DeletePlant(ctx, adminID, plantID, dto.AdminDeletePlantReq{})
+		assert.ErrorIs(t, err, models.ErrPlantNotFound)
+	})
+
+	t.Run("every action is audited", func(t *testing.T) {
+		entries, hasMore, err := adminService.GetAuditLog(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.False(t, hasMore)
+
+		actions := make([]models.AuditAction, 0, len(entries))
+		for _, entry := range entries {
+			assert.Equal(t, adminID, *entry.ActorID)
+			actions = append(actions, entry.Action)
+		}
+		assert.ElementsMatch(t, []models.AuditAction{
+			models.AuditActionRenameUser,
+			models.AuditActionRenamePlant,
+			models.AuditActionGrantSeeds,
+			models.AuditActionRevivePlant,
+			models.AuditActionSetRole,
+			models.AuditActionDeletePlant,
+		}, actions)
+	})
+
+	t.Run("stats are counted", func(t *testing.T) {
+		stats, err := adminService.GetServerStats(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), stats.Users)
+		assert.Equal(t, int64(0), stats.AlivePlants)
+		assert.Equal(t, int64(3), stats.UnplantedSeeds)
+	})
+}
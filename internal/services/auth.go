@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
 	"slices"
 	"strings"
 	"time"
@@ -14,7 +15,10 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/mailer"
 	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/signing"
 	"github.com/jasonuc/moota/internal/store"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -25,42 +29,116 @@ var (
 	ErrInvalidEmail                   = errors.New("invalid email")
 	ErrInvalidRefreshToken            = errors.New("invalid refresh token")
 	ErrTokenExpiredOrRevoked          = errors.New("token expired or revoked")
+	ErrRefreshTokenReused             = errors.New("refresh token reused")
+	ErrAccessTokenRevoked             = errors.New("access token revoked")
+	ErrInvalidVerificationToken       = errors.New("invalid or expired verification token")
+	ErrNoEmailToVerify                = errors.New("user has no email to verify")
+	ErrEmailAlreadyVerified           = errors.New("email already verified")
+	ErrInvalidPasswordResetToken      = errors.New("invalid or expired password reset token")
+	ErrTooManyRequests                = errors.New("too many requests")
+	ErrTooManyLoginAttempts           = errors.New("too many failed login attempts")
 	ErrUsernameTooLong                = errors.New("username must be between 3 and 30 characters")
 	ErrUsernameMustContainOnlyLetters = errors.New("username must contain only letters")
 	ErrUsernameTaken                  = errors.New("username already in use")
+	ErrNoPasswordSet                  = errors.New("a password must be set to confirm this")
+	ErrPasswordAlreadySet             = errors.New("a password is already set")
 )
 
+// RetryAfterError is returned when a request is refused for now but can be retried once RetryAfter has passed.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// AuthEmailLinks are the pages the links in auth emails point to. The token is added to them as a query param.
+type AuthEmailLinks struct {
+	VerifyEmailURL   string
+	ResetPasswordURL string
+}
+
+// LoginThrottle is how failed logins are throttled for each username and for each IP address.
+type LoginThrottle struct {
+	Username  models.LoginThrottlePolicy
+	IPAddress models.LoginThrottlePolicy
+}
+
 type AuthService interface {
-	Register(context.Context, dto.UserRegisterReq) (*models.User, *models.TokenPair, error)
-	Login(context.Context, dto.UserLoginReq) (*models.TokenPair, error)
-	RefreshAccessToken(context.Context, string) (*models.TokenPair, error)
+	Register(context.Context, dto.UserRegisterReq, models.ClientInfo) (*models.User, *models.TokenPair, error)
+	Login(context.Context, dto.UserLoginReq, models.ClientInfo) (*models.TokenPair, error)
+	StartSession(context.Context, *models.User, models.ClientInfo) (*models.TokenPair, error)
+	RefreshAccessToken(context.Context, string, models.ClientInfo) (*models.TokenPair, error)
+	Logout(context.Context, string) error
+	GetSessions(context.Context, string, string) ([]*models.Session, error)
+	RevokeSession(context.Context, string, string) error
+	RevokeOtherSessions(context.Context, string, string) (*models.TokenPair, error)
 	VerifyAccessToken(context.Context, string) (string, error)
+	GetUserRole(context.Context, string) (models.Role, error)
 	ChangeUserUsername(context.Context, string, dto.ChangeUsernameReq) (*models.User, error)
 	ChangeUserEmail(context.Context, string, dto.ChangeEmailReq) (*models.User, error)
-	ChangeUserPassword(context.Context, string, dto.ChangePasswordReq) (*models.User, error)
+	ChangeUserPassword(context.Context, string, dto.ChangePasswordReq, models.ClientInfo) (*models.User, *models.TokenPair, error)
+	LogoutEverywhere(context.Context, string) error
+	RequestEmailVerification(context.Context, string) error
+	VerifyEmail(context.Context, dto.VerifyEmailReq) (*models.User, error)
+	RequestPasswordReset(context.Context, dto.RequestPasswordResetReq, models.ClientInfo) error
+	ConfirmPasswordReset(context.Context, dto.ConfirmPasswordResetReq) error
+	IssuePasswordSetToken(context.Context, string) (string, error)
+	DeleteAccount(context.Context, string, dto.DeleteAccountReq) (*time.Time, error)
+	DeleteScheduledAccounts(context.Context, int) (int, error)
 	GetAccessTokenTTL() int
 	GetRefreshTokenTTL() int
+	JWKS() signing.JWKS
+}
+
+// accessTokenClaims are the claims of an access token. An access token is only accepted while its
+// TokenVersion matches the user's, so bumping the user's version revokes every access token issued before.
+type accessTokenClaims struct {
+	TokenVersion int64 `json:"ver"`
+	jwt.RegisteredClaims
 }
 
 type authService struct {
 	store           *store.Store
-	accessSecret    []byte
+	keySet          *signing.KeySet
 	refreshTokenTTL time.Duration
 	accessTokenTTL  time.Duration
 	issuer          string
+	clock           models.Clock
+
+	mailer        mailer.Mailer
+	emailLinks    AuthEmailLinks
+	loginThrottle LoginThrottle
+	contentFilter moderation.ContentFilter
+
+	// how long a user has to change their mind after deleting their account, they are deleted straight away when it is 0
+	accountDeletionGracePeriod time.Duration
 }
 
-func NewAuthService(store *store.Store, acessSecret []byte, refreshTTL, acessTTL time.Duration, issuer string) AuthService {
+func NewAuthService(store *store.Store, keySet *signing.KeySet, refreshTTL, acessTTL time.Duration, issuer string, clock models.Clock, mailer mailer.Mailer, emailLinks AuthEmailLinks, loginThrottle LoginThrottle, contentFilter moderation.ContentFilter, accountDeletionGracePeriod time.Duration) AuthService {
 	return &authService{
 		store:           store,
-		accessSecret:    acessSecret,
+		keySet:          keySet,
 		refreshTokenTTL: refreshTTL,
 		accessTokenTTL:  acessTTL,
 		issuer:          issuer,
+		clock:           clock,
+		mailer:          mailer,
+		emailLinks:      emailLinks,
+		loginThrottle:   loginThrottle,
+		contentFilter:   contentFilter,
+
+		accountDeletionGracePeriod: accountDeletionGracePeriod,
 	}
 }
 
-func (s *authService) Register(ctx context.Context, dto dto.UserRegisterReq) (*models.User, *models.TokenPair, error) {
+func (s *authService) Register(ctx context.Context, dto dto.UserRegisterReq, client models.ClientInfo) (*models.User, *models.TokenPair, error) {
 
 	_, err := s.store.User.GetByUsername(ctx, dto.Username)
 	if err == nil {
@@ -71,6 +149,10 @@ func (s *authService) Register(ctx context.Context, dto dto.UserRegisterReq) (*m
 		return nil, nil, err
 	}
 
+	if err := s.contentFilter.Check(moderation.FieldUsername, dto.Username); err != nil {
+		return nil, nil, err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(dto.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to hash password: %w", err)
@@ -87,36 +169,47 @@ func (s *authService) Register(ctx context.Context, dto dto.UserRegisterReq) (*m
 		return nil, nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	accessToken, err := s.generateAccessToken(user)
+	tokenPair, err := s.StartSession(ctx, user, client)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	refreshToken, err := s.generateRefreshToken(user)
-	if err != nil {
-		return nil, nil, err
-	}
+	return user, tokenPair, nil
+}
 
-	if err := s.store.RefreshToken.Insert(ctx, refreshToken); err != nil {
-		return nil, nil, err
-	}
+func (s *authService) Login(ctx context.Context, dto dto.UserLoginReq, client models.ClientInfo) (*models.TokenPair, error) {
+	usernameKey := models.UsernameLoginFailuresKey(dto.Username)
+	ipAddressKey := models.IPAddressLoginFailuresKey(client.IPAddress)
 
-	tokenPair := &models.TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken.Plain,
+	if err := s.checkLoginThrottle(ctx, usernameKey, ipAddressKey); err != nil {
+		return nil, err
 	}
 
-	return user, tokenPair, nil
-}
-
-func (s *authService) Login(ctx context.Context, dto dto.UserLoginReq) (*models.TokenPair, error) {
 	user, err := s.store.User.GetByUsername(ctx, dto.Username)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, s.recordLoginFailure(ctx, usernameKey, ipAddressKey)
 	}
 
 	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(dto.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, s.recordLoginFailure(ctx, usernameKey, ipAddressKey)
+	}
+
+	// failures from the IP address are kept so one account the attacker owns can't be used to reset them
+	if err := s.store.LoginFailures.Clear(ctx, usernameKey); err != nil {
+		return nil, err
+	}
+
+	return s.StartSession(ctx, user, client)
+}
+
+// StartSession logs the user in on a new session, for when they have already proven who they are.
+// Logging in cancels the account's deletion if it is scheduled.
+func (s *authService) StartSession(ctx context.Context, user *models.User, client models.ClientInfo) (*models.TokenPair, error) {
+	if user.DeletionScheduledAt != nil {
+		if err := s.store.User.SetDeletionScheduledAt(ctx, user.ID, nil); err != nil {
+			return nil, err
+		}
+		user.DeletionScheduledAt = nil
 	}
 
 	accessToken, err := s.generateAccessToken(user)
@@ -124,7 +217,7 @@ func (s *authService) Login(ctx context.Context, dto dto.UserLoginReq) (*models.
 		return nil, err
 	}
 
-	refreshToken, err := s.generateRefreshToken(user)
+	refreshToken, err := s.generateRefreshToken(user, client)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +232,7 @@ func (s *authService) Login(ctx context.Context, dto dto.UserLoginReq) (*models.
 	}, nil
 }
 
-func (s *authService) RefreshAccessToken(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+func (s *authService) RefreshAccessToken(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.TokenPair, error) {
 	transaction, err := s.store.Begin()
 	if err != nil {
 		return nil, store.ErrTransactionCouldNotStart
@@ -155,7 +248,21 @@ func (s *authService) RefreshAccessToken(ctx context.Context, refreshToken strin
 		return nil, ErrInvalidRefreshToken
 	}
 
-	if token.ExpiresAt.Before(time.Now()) || token.RevokedAt != nil {
+	// a refresh token is only ever used once, so seeing a revoked one again means it has leaked.
+	// every token descended from the same login is revoked so whoever holds the newest one is signed out too
+	if token.RevokedAt != nil {
+		if err := tx.RefreshToken.RevokeFamily(ctx, token.FamilyID); err != nil {
+			return nil, err
+		}
+
+		if err := transaction.Commit(); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	if token.ExpiresAt.Before(s.clock.Now()) {
 		return nil, ErrTokenExpiredOrRevoked
 	}
 
@@ -169,40 +276,219 @@ func (s *authService) RefreshAccessToken(ctx context.Context, refreshToken strin
 		return nil, err
 	}
 
+	newRefreshToken, err := s.generateRefreshToken(user, client)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken.FamilyID = token.FamilyID
+	newRefreshToken.ParentID = &token.ID
+	newRefreshToken.SessionCreatedAt = token.SessionCreatedAt
+
+	if err := tx.RefreshToken.Revoke(ctx, token.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.RefreshToken.Insert(ctx, newRefreshToken); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken.Plain,
+	}, nil
+}
+
+// checkLoginThrottle refuses the login if either the username or the IP address has failed too many times recently.
+func (s *authService) checkLoginThrottle(ctx context.Context, usernameKey, ipAddressKey string) error {
+	now := s.clock.Now()
+
+	blockedUntil, err := s.loginBlockedUntil(ctx, usernameKey, s.loginThrottle.Username, now)
+	if err != nil {
+		return err
+	}
+
+	ipBlockedUntil, err := s.loginBlockedUntil(ctx, ipAddressKey, s.loginThrottle.IPAddress, now)
+	if err != nil {
+		return err
+	}
+
+	if ipBlockedUntil.After(blockedUntil) {
+		blockedUntil = ipBlockedUntil
+	}
+
+	if blockedUntil.After(now) {
+		return &RetryAfterError{Err: ErrTooManyLoginAttempts, RetryAfter: blockedUntil.Sub(now)}
+	}
+
+	return nil
+}
+
+func (s *authService) loginBlockedUntil(ctx context.Context, key string, policy models.LoginThrottlePolicy, now time.Time) (time.Time, error) {
+	failures, err := s.store.LoginFailures.Get(ctx, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return policy.BlockedUntil(failures, now), nil
+}
+
+// recordLoginFailure counts the failed login against the username and IP address and returns ErrInvalidCredentials.
+func (s *authService) recordLoginFailure(ctx context.Context, usernameKey, ipAddressKey string) error {
+	now := s.clock.Now()
+
+	if _, err := s.store.LoginFailures.Record(ctx, usernameKey, now, now.Add(-s.loginThrottle.Username.ResetAfter)); err != nil {
+		return err
+	}
+
+	if _, err := s.store.LoginFailures.Record(ctx, ipAddressKey, now, now.Add(-s.loginThrottle.IPAddress.ResetAfter)); err != nil {
+		return err
+	}
+
+	return ErrInvalidCredentials
+}
+
+// Logout revokes the session the refresh token belongs to.
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	tokenHash := sha256.Sum256([]byte(refreshToken))
+
+	token, err := s.store.RefreshToken.GetByHash(ctx, tokenHash[:])
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.store.RefreshToken.RevokeFamily(ctx, token.FamilyID)
+}
+
+// GetSessions lists the user's active sessions, marking the one currentRefreshToken belongs to.
+func (s *authService) GetSessions(ctx context.Context, userID, currentRefreshToken string) ([]*models.Session, error) {
+	sessions, err := s.store.RefreshToken.GetSessionsByUserID(ctx, userID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	currentSessionID, err := s.getSessionID(ctx, s.store, userID, currentRefreshToken)
+	if err != nil {
+		return sessions, nil
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the session's refresh token so it cannot be renewed.
+// Access tokens already handed to it are left to expire, which takes at most the access token TTL,
+// so that the user's other sessions are not signed out along with it.
+func (s *authService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	return s.store.RefreshToken.RevokeSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the session currentRefreshToken belongs to.
+// The token version is bumped so the other sessions' access tokens stop working straight away,
+// which means the current session needs a new access token too. It is returned with the unchanged refresh token.
+func (s *authService) RevokeOtherSessions(ctx context.Context, userID, currentRefreshToken string) (*models.TokenPair, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	currentSessionID, err := s.getSessionID(ctx, tx, userID, currentRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.RefreshToken.RevokeOtherSessions(ctx, userID, currentSessionID); err != nil {
+		return nil, err
+	}
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, models.ErrUserNotFound
+	}
+
+	user.TokenVersion, err = tx.User.IncrementTokenVersion(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.generateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := transaction.Commit(); err != nil {
 		return nil, err
 	}
 
 	return &models.TokenPair{
 		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		RefreshToken: currentRefreshToken,
 	}, nil
 }
 
+// getSessionID returns the ID of the session the refresh token belongs to if it is one of the user's valid tokens.
+func (s *authService) getSessionID(ctx context.Context, tx *store.Store, userID, refreshToken string) (string, error) {
+	tokenHash := sha256.Sum256([]byte(refreshToken))
+
+	token, err := tx.RefreshToken.GetByHash(ctx, tokenHash[:])
+	if err != nil || token.UserID != userID {
+		return "", ErrInvalidRefreshToken
+	}
+
+	if token.RevokedAt != nil || token.ExpiresAt.Before(s.clock.Now()) {
+		return "", ErrTokenExpiredOrRevoked
+	}
+
+	return token.FamilyID, nil
+}
+
 func (s *authService) VerifyAccessToken(ctx context.Context, accessToken string) (string, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.accessSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(accessToken, &accessTokenClaims{}, s.keySet.Keyfunc,
+		jwt.WithValidMethods(s.keySet.ValidMethods()), jwt.WithTimeFunc(s.clock.Now))
 
 	if err != nil {
 		return "", err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*accessTokenClaims)
 	if ok && token.Valid {
 		userID, err := claims.GetSubject()
 		if err != nil {
 			return "", fmt.Errorf("invalid user id in token: %w", err)
 		}
+
+		tokenVersion, err := s.store.User.GetTokenVersion(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+
+		if claims.TokenVersion != tokenVersion {
+			return "", ErrAccessTokenRevoked
+		}
+
 		return userID, nil
 	}
 
 	return "", errors.New("invalid token")
 }
 
+func (s *authService) GetUserRole(ctx context.Context, userID string) (models.Role, error) {
+	user, err := s.store.User.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
 func (s *authService) ChangeUserUsername(ctx context.Context, userID string, dto dto.ChangeUsernameReq) (*models.User, error) {
 	transaction, err := s.store.Begin()
 	if err != nil {
@@ -226,6 +512,10 @@ func (s *authService) ChangeUserUsername(ctx context.Context, userID string, dto
 		return nil, err
 	}
 
+	if err := s.contentFilter.Check(moderation.FieldUsername, dto.NewUsername); err != nil {
+		return nil, err
+	}
+
 	user.Username = dto.NewUsername
 	if err := tx.User.Update(ctx, user); err != nil {
 		return nil, err
@@ -252,13 +542,19 @@ func (s *authService) ChangeUserEmail(ctx context.Context, userID string, dto dt
 		return nil, ErrInvalidEmail
 	}
 
-	user, err := tx.User.GetByID(ctx, userID)
+	user, err := tx.User.GetByIDForUpdate(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	user.Email = dto.NewEmail
-	if err := tx.User.Update(ctx, user); err != nil {
+	user.EmailVerified = false
+	if err := tx.User.UpdateEmail(ctx, user.ID, user.Email); err != nil {
+		return nil, err
+	}
+
+	token, err := s.issueEmailVerificationToken(ctx, tx, user)
+	if err != nil {
 		return nil, err
 	}
 
@@ -266,10 +562,52 @@ func (s *authService) ChangeUserEmail(ctx context.Context, userID string, dto dt
 		return nil, err
 	}
 
+	// the email is only handed to the mailer once the new address and token are saved
+	if err := s.sendEmailVerification(ctx, user, token); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
-func (s *authService) ChangeUserPassword(ctx context.Context, userID string, dto dto.ChangePasswordReq) (*models.User, error) {
+// RequestEmailVerification sends the user a new verification email, invalidating any sent before.
+func (s *authService) RequestEmailVerification(ctx context.Context, userID string) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Email == "" {
+		return ErrNoEmailToVerify
+	}
+
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueEmailVerificationToken(ctx, tx, user)
+	if err != nil {
+		return err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return err
+	}
+
+	return s.sendEmailVerification(ctx, user, token)
+}
+
+// VerifyEmail marks the user's email as verified. The token is rejected if the user has changed their email since it was sent.
+func (s *authService) VerifyEmail(ctx context.Context, dto dto.VerifyEmailReq) (*models.User, error) {
 	transaction, err := s.store.Begin()
 	if err != nil {
 		return nil, store.ErrTransactionCouldNotStart
@@ -279,29 +617,214 @@ func (s *authService) ChangeUserPassword(ctx context.Context, userID string, dto
 
 	tx := s.store.WithTx(transaction)
 
+	tokenHash := sha256.Sum256([]byte(dto.Token))
+
+	token, err := tx.EmailVerificationToken.GetByHash(ctx, tokenHash[:])
+	if err != nil {
+		if errors.Is(err, models.ErrEmailVerificationTokenNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if !token.Usable(now) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := tx.User.GetByIDForUpdate(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(user.Email, token.Email) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user.EmailVerified = true
+	if err := tx.User.SetEmailVerified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.EmailVerificationToken.MarkUsed(ctx, token.ID, now); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// issueEmailVerificationToken replaces the user's outstanding verification tokens with a new one for their current email.
+func (s *authService) issueEmailVerificationToken(ctx context.Context, tx *store.Store, user *models.User) (*models.EmailVerificationToken, error) {
+	now := s.clock.Now()
+
+	if err := tx.EmailVerificationToken.InvalidateByUserID(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+
+	plain, hash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate email verification token: %w", err)
+	}
+
+	token := &models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Hash:      hash,
+		Plain:     plain,
+		CreatedAt: now,
+		ExpiresAt: now.Add(models.EmailVerificationTokenTTL),
+	}
+
+	if err := tx.EmailVerificationToken.Insert(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// sendEmailVerification emails the verification token to the address it was issued for.
+func (s *authService) sendEmailVerification(ctx context.Context, user *models.User, token *models.EmailVerificationToken) error {
+	link := withToken(s.emailLinks.VerifyEmailURL, token.Plain)
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Moota email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to confirm %s is your email address. It expires in %s.\n\n%s\n\nIf you did not ask for this you can ignore this email.\n",
+			user.Username, user.Email, models.EmailVerificationTokenTTL, link,
+		),
+	})
+}
+
+// ChangeUserPassword signs the user out everywhere and returns a new token pair for the client that changed it.
+func (s *authService) ChangeUserPassword(ctx context.Context, userID string, dto dto.ChangePasswordReq, client models.ClientInfo) (*models.User, *models.TokenPair, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
 	user, err := tx.User.GetByID(ctx, userID)
 	if err != nil {
-		return nil, models.ErrUserNotFound
+		return nil, nil, models.ErrUserNotFound
 	}
 
-	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(dto.OldPassword)); err != nil && errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-		return nil, ErrInvalidCredentials
+	if len(user.PasswordHash) == 0 {
+		return nil, nil, ErrNoPasswordSet
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(dto.OldPassword)); err != nil {
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	newPasswordHash, err := bcrypt.GenerateFromPassword([]byte(dto.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	user.PasswordHash = newPasswordHash
 	if err := tx.User.Update(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
+	if err := revokeAllTokens(ctx, tx, user); err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := s.generateAccessToken(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := s.generateRefreshToken(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.RefreshToken.Insert(ctx, refreshToken); err != nil {
+		return nil, nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	tokenPair := &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken.Plain,
+	}
+
+	return user, tokenPair, nil
+}
+
+// LogoutEverywhere revokes every access and refresh token issued to the user.
+func (s *authService) LogoutEverywhere(ctx context.Context, userID string) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := revokeAllTokens(ctx, tx, user); err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+// DeleteAccount deletes the user once they have confirmed their password. With a grace period the account is
+// only scheduled for deletion and they are logged out everywhere, so logging back in before it passes keeps it.
+// It returns when the account will be deleted, or nil if it already has been.
+func (s *authService) DeleteAccount(ctx context.Context, userID string, dto dto.DeleteAccountReq) (*time.Time, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(user.PasswordHash) == 0 {
+		return nil, ErrNoPasswordSet
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(dto.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if s.accountDeletionGracePeriod <= 0 {
+		if err := tx.User.Delete(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		return nil, transaction.Commit()
+	}
+
+	deletionScheduledAt := s.clock.Now().Add(s.accountDeletionGracePeriod)
+	if err := tx.User.SetDeletionScheduledAt(ctx, user.ID, &deletionScheduledAt); err != nil {
 		return nil, err
 	}
 
-	// this current approach to change password would revoke all the user's refresh tokens which in effect signs them out when the refresh token expires
-	// the current approach does have a problem though. It is going to weight until the user needs to refresh their access token before they'd be logged out so...
-	// TODO: improve maybe return a new token pair
-	if err := tx.RefreshToken.RevokeManyByUserID(ctx, userID); err != nil {
+	if err := revokeAllTokens(ctx, tx, user); err != nil {
 		return nil, err
 	}
 
@@ -309,22 +832,41 @@ func (s *authService) ChangeUserPassword(ctx context.Context, userID string, dto
 		return nil, err
 	}
 
-	return user, nil
+	return &deletionScheduledAt, nil
 }
 
-func (s *authService) generateAccessToken(user *models.User) (string, error) {
-	now := time.Now()
-	accessExp := time.Now().Add(s.accessTokenTTL)
+// DeleteScheduledAccounts deletes up to limit accounts whose grace period has passed and returns how many it deleted.
+func (s *authService) DeleteScheduledAccounts(ctx context.Context, limit int) (int, error) {
+	return s.store.User.DeleteScheduledBefore(ctx, s.clock.Now(), limit)
+}
+
+// revokeAllTokens bumps the user's token version so their access tokens are rejected straight away
+// and revokes their refresh tokens so they cannot be swapped for new ones.
+func revokeAllTokens(ctx context.Context, tx *store.Store, user *models.User) error {
+	tokenVersion, err := tx.User.IncrementTokenVersion(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	user.TokenVersion = tokenVersion
+
+	return tx.RefreshToken.RevokeManyByUserID(ctx, user.ID)
+}
 
-	claims := jwt.RegisteredClaims{
-		Subject:   user.ID,
-		ExpiresAt: jwt.NewNumericDate(accessExp),
-		IssuedAt:  jwt.NewNumericDate(now),
-		Issuer:    s.issuer,
+func (s *authService) generateAccessToken(user *models.User) (string, error) {
+	now := s.clock.Now()
+	accessExp := now.Add(s.accessTokenTTL)
+
+	claims := accessTokenClaims{
+		TokenVersion: user.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			ExpiresAt: jwt.NewNumericDate(accessExp),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.issuer,
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessToken, err := token.SignedString(s.accessSecret)
+	accessToken, err := s.keySet.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("could not generate access token: %w", err)
 	}
@@ -340,27 +882,243 @@ func (s *authService) GetRefreshTokenTTL() int {
 	return int(s.refreshTokenTTL)
 }
 
-func (s *authService) generateRefreshToken(user *models.User) (*models.RefreshToken, error) {
-	now := time.Now()
-	refreshExp := time.Now().Add(s.refreshTokenTTL)
+// JWKS is the set of public keys access tokens can be verified with.
+func (s *authService) JWKS() signing.JWKS {
+	return s.keySet.JWKS()
+}
+
+func (s *authService) generateRefreshToken(user *models.User, client models.ClientInfo) (*models.RefreshToken, error) {
+	now := s.clock.Now()
+	refreshExp := now.Add(s.refreshTokenTTL)
 
-	refreshTokenBytes := make([]byte, 32)
-	if _, err := rand.Read(refreshTokenBytes); err != nil {
+	refreshTokenPlain, refreshTokenHash, err := generateOpaqueToken()
+	if err != nil {
 		return nil, fmt.Errorf("could not generate refresh token %w", err)
 	}
 
-	refreshTokenPlain := base64.RawURLEncoding.EncodeToString(refreshTokenBytes)
-	refreshTokenHash := sha256.Sum256([]byte(refreshTokenPlain))
-
 	return &models.RefreshToken{
 		UserID:    user.ID,
-		Hash:      refreshTokenHash[:],
+		Hash:      refreshTokenHash,
 		Plain:     refreshTokenPlain,
 		CreatedAt: now,
 		ExpiresAt: refreshExp,
+		Client:    client,
 	}, nil
 }
 
+// RequestPasswordReset emails a password reset link if the email belongs to an account and has been verified.
+// Whether it did is never revealed. Only the per IP rate limit is reported, since it is the same for every email.
+func (s *authService) RequestPasswordReset(ctx context.Context, dto dto.RequestPasswordResetReq, client models.ClientInfo) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	now := s.clock.Now()
+	windowStart := now.Add(-models.PasswordResetRateLimitWindow)
+
+	ipRequests, oldestIPRequest, err := tx.PasswordReset.GetRequestsByIPAddressSince(ctx, client.IPAddress, windowStart)
+	if err != nil {
+		return err
+	}
+
+	if ipRequests >= models.PasswordResetMaxRequestsPerIPAddress && oldestIPRequest != nil {
+		return &RetryAfterError{
+			Err:        ErrTooManyRequests,
+			RetryAfter: oldestIPRequest.Add(models.PasswordResetRateLimitWindow).Sub(now),
+		}
+	}
+
+	request := &models.PasswordResetRequest{IPAddress: client.IPAddress, RequestedAt: now}
+
+	user, err := tx.User.GetByEmail(ctx, dto.Email)
+	if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		return err
+	}
+
+	// an unverified email could be a typo that belongs to someone else, so it can't be trusted with a reset link
+	sendReset := false
+	if user != nil && user.EmailVerified {
+		request.UserID = &user.ID
+
+		accountRequests, err := tx.PasswordReset.CountRequestsByUserIDSince(ctx, user.ID, windowStart)
+		if err != nil {
+			return err
+		}
+		sendReset = accountRequests < models.PasswordResetMaxRequestsPerAccount
+	}
+
+	if err := tx.PasswordReset.InsertRequest(ctx, request); err != nil {
+		return err
+	}
+
+	var token *models.PasswordResetToken
+	if sendReset {
+		if token, err = s.issuePasswordResetToken(ctx, tx, user); err != nil {
+			return err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return err
+	}
+
+	// the email is only handed to the mailer once the token is saved, and the mailer sends it in the background
+	// so asking for a reset takes as long whether or not the account exists
+	if token != nil {
+		return s.sendPasswordReset(ctx, user, token)
+	}
+
+	return nil
+}
+
+// ConfirmPasswordReset sets the new password and signs the user out everywhere.
+func (s *authService) ConfirmPasswordReset(ctx context.Context, dto dto.ConfirmPasswordResetReq) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	tokenHash := sha256.Sum256([]byte(dto.Token))
+
+	token, err := tx.PasswordReset.GetTokenByHash(ctx, tokenHash[:])
+	if err != nil {
+		if errors.Is(err, models.ErrPasswordResetTokenNotFound) {
+			return ErrInvalidPasswordResetToken
+		}
+		return err
+	}
+
+	now := s.clock.Now()
+	if !token.Usable(now) {
+		return ErrInvalidPasswordResetToken
+	}
+
+	// hashed before the user is locked so the row isn't held while bcrypt runs
+	newPasswordHash, err := bcrypt.GenerateFromPassword([]byte(dto.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user, err := tx.User.GetByIDForUpdate(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = newPasswordHash
+	if err := tx.User.UpdatePasswordHash(ctx, user.ID, user.PasswordHash); err != nil {
+		return err
+	}
+
+	if err := tx.PasswordReset.InvalidateTokensByUserID(ctx, user.ID, now); err != nil {
+		return err
+	}
+
+	if err := revokeAllTokens(ctx, tx, user); err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+// IssuePasswordSetToken returns a password reset token for a user who has no password yet, which they confirm
+// like any other reset to set one. Only call it once the user has proven who they are some other way.
+func (s *authService) IssuePasswordSetToken(ctx context.Context, userID string) (string, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return "", store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByIDForUpdate(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(user.PasswordHash) != 0 {
+		return "", ErrPasswordAlreadySet
+	}
+
+	token, err := s.issuePasswordResetToken(ctx, tx, user)
+	if err != nil {
+		return "", err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return "", err
+	}
+
+	return token.Plain, nil
+}
+
+// sendPasswordReset emails the password reset token to the user.
+func (s *authService) sendPasswordReset(ctx context.Context, user *models.User, token *models.PasswordResetToken) error {
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Moota password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to choose a new password. It expires in %s.\n\n%s\n\nIf you did not ask for this you can ignore this email, your password has not been changed.\n",
+			user.Username, models.PasswordResetTokenTTL, withToken(s.emailLinks.ResetPasswordURL, token.Plain),
+		),
+	})
+}
+
+// issuePasswordResetToken replaces the user's outstanding password reset tokens with a new one.
+func (s *authService) issuePasswordResetToken(ctx context.Context, tx *store.Store, user *models.User) (*models.PasswordResetToken, error) {
+	now := s.clock.Now()
+
+	if err := tx.PasswordReset.InvalidateTokensByUserID(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+
+	plain, hash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate password reset token: %w", err)
+	}
+
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		Hash:      hash,
+		Plain:     plain,
+		CreatedAt: now,
+		ExpiresAt: now.Add(models.PasswordResetTokenTTL),
+	}
+
+	if err := tx.PasswordReset.InsertToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func withToken(pageURL, token string) string {
+	return pageURL + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// generateOpaqueToken returns a random token to give to the user and the hash of it to store.
+func generateOpaqueToken() (string, []byte, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", nil, err
+	}
+
+	plain := base64.RawURLEncoding.EncodeToString(tokenBytes)
+	hash := sha256.Sum256([]byte(plain))
+
+	return plain, hash[:], nil
+}
+
 var invalidUsernames = []string{"moota"}
 
 func isValidUsername(username string) error {
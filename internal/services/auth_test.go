@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEmailLinks = AuthEmailLinks{
+	VerifyEmailURL:   "http://localhost/verify-email",
+	ResetPasswordURL: "http://localhost/reset-password",
+}
+
+// lastSentToken pulls the token out of the link in the last email sent
+func lastSentToken(t *testing.T, mailer *fakeMailer) string {
+	t.Helper()
+	if len(mailer.sent) == 0 {
+		t.Fatal("expected an email to be sent")
+	}
+	body := mailer.sent[len(mailer.sent)-1].Body
+	_, after, found := strings.Cut(body, "?token=")
+	if !found {
+		t.Fatalf("expected a link with a token in %q", body)
+	}
+	token, _, _ := strings.Cut(after, "\n")
+	return token
+}
+
+var testLoginThrottle = LoginThrottle{
+	Username:  models.LoginThrottlePolicy{FreeFailures: 3, BaseDelay: time.Second, MaxDelay: time.Minute, LockoutAfter: 5, LockoutDuration: 15 * time.Minute, ResetAfter: 24 * time.Hour},
+	IPAddress: models.LoginThrottlePolicy{FreeFailures: 20, BaseDelay: time.Second, MaxDelay: time.Minute, LockoutAfter: 100, LockoutDuration: 15 * time.Minute, ResetAfter: 24 * time.Hour},
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, &fakeMailer{}, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+
+	ctx := context.Background()
+	client := models.NewClientInfo("test-agent", "127.0.0.1")
+	_, tokenPair, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rotated string
+
+	t.Run("refreshing rotates the refresh token", func(t *testing.T) {
+		newTokenPair, err := authService.RefreshAccessToken(ctx, tokenPair.RefreshToken, client)
+		assert.NoError(t, err)
+		assert.NotEqual(t, tokenPair.RefreshToken, newTokenPair.RefreshToken)
+		rotated = newTokenPair.RefreshToken
+	})
+
+	t.Run("reusing a rotated token revokes the family", func(t *testing.T) {
+		_, err := authService.RefreshAccessToken(ctx, tokenPair.RefreshToken, client)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+		_, err = authService.RefreshAccessToken(ctx, rotated, client)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused, "expected the newest token in the family to be revoked too")
+	})
+
+	t.Run("other logins are unaffected", func(t *testing.T) {
+		otherLogin, err := authService.Login(ctx, dto.UserLoginReq{Username: "testuser", Password: "password123"}, client)
+		assert.NoError(t, err)
+
+		_, err = authService.RefreshAccessToken(ctx, otherLogin.RefreshToken, client)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired tokens cannot be refreshed", func(t *testing.T) {
+		login, err := authService.Login(ctx, dto.UserLoginReq{Username: "testuser", Password: "password123"}, client)
+		assert.NoError(t, err)
+
+		clock.Advance(8 * 24 * time.Hour)
+
+		_, err = authService.RefreshAccessToken(ctx, login.RefreshToken, client)
+		assert.ErrorIs(t, err, ErrTokenExpiredOrRevoked)
+	})
+}
+
+func TestSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, &fakeMailer{}, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+
+	ctx := context.Background()
+	user, phone, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, models.NewClientInfo("phone", "10.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+	laptop, err := authService.Login(ctx, dto.UserLoginReq{Username: "testuser", Password: "password123"}, models.NewClientInfo("laptop", "10.0.0.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+	tablet, err := authService.Login(ctx, dto.UserLoginReq{Username: "testuser", Password: "password123"}, models.NewClientInfo("tablet", "10.0.0.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("lists every session with its client", func(t *testing.T) {
+		clock.Advance(time.Minute)
+		phone, err = authService.RefreshAccessToken(ctx, phone.RefreshToken, models.NewClientInfo("phone", "10.0.0.4"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sessions, err := authService.GetSessions(ctx, user.ID, phone.RefreshToken)
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 3) {
+			assert.Equal(t, "phone", sessions[0].UserAgent, "expected the most recently used session first")
+			assert.Equal(t, "10.0.0.4", sessions[0].IPAddress)
+			assert.True(t, sessions[0].Current)
+			assert.True(t, sessions[0].LastUsedAt.After(sessions[0].CreatedAt), "expected rotation to keep the session's start time")
+			assert.False(t, sessions[1].Current)
+		}
+	})
+
+	t.Run("revoking a session signs it out", func(t *testing.T) {
+		sessions, err := authService.GetSessions(ctx, user.ID, tablet.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, session := range sessions {
+			if session.Current {
+				assert.NoError(t, authService.RevokeSession(ctx, user.ID, session.ID))
+			}
+		}
+
+		_, err = authService.RefreshAccessToken(ctx, tablet.RefreshToken, models.NewClientInfo("tablet", "10.0.0.3"))
+		assert.Error(t, err)
+
+		assert.ErrorIs(t, authService.RevokeSession(ctx, user.ID, "not-a-session"), models.ErrSessionNotFound)
+	})
+
+	t.Run("revoking other sessions keeps the current one", func(t *testing.T) {
+		oldPhone := phone
+		phone, err = authService.RevokeOtherSessions(ctx, user.ID, phone.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, oldPhone.RefreshToken, phone.RefreshToken)
+
+		_, err = authService.VerifyAccessToken(ctx, laptop.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+		_, err = authService.VerifyAccessToken(ctx, oldPhone.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+		userID, err := authService.VerifyAccessToken(ctx, phone.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+
+		sessions, err := authService.GetSessions(ctx, user.ID, phone.RefreshToken)
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			assert.True(t, sessions[0].Current)
+		}
+
+		_, err = authService.RefreshAccessToken(ctx, laptop.RefreshToken, models.NewClientInfo("laptop", "10.0.0.2"))
+		assert.Error(t, err)
+	})
+
+	t.Run("logging out revokes the session", func(t *testing.T) {
+		err := authService.Logout(ctx, phone.RefreshToken)
+		assert.NoError(t, err)
+
+		sessions, err := authService.GetSessions(ctx, user.ID, "")
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+}
+
+func TestAccessTokenRevocation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, &fakeMailer{}, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+
+	ctx := context.Background()
+	client := models.NewClientInfo("test-agent", "127.0.0.1")
+	user, oldTokenPair, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var newTokenPair *models.TokenPair
+
+	t.Run("changing password revokes old tokens and issues new ones", func(t *testing.T) {
+		_, newTokenPair, err = authService.ChangeUserPassword(ctx, user.ID, dto.ChangePasswordReq{OldPassword: "password123", NewPassword: "password456"}, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := authService.VerifyAccessToken(ctx, oldTokenPair.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+		_, err = authService.RefreshAccessToken(ctx, oldTokenPair.RefreshToken, client)
+		assert.Error(t, err)
+
+		userID, err := authService.VerifyAccessToken(ctx, newTokenPair.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+	})
+
+	t.Run("logging out everywhere revokes every token", func(t *testing.T) {
+		err := authService.LogoutEverywhere(ctx, user.ID)
+		assert.NoError(t, err)
+
+		_, err = authService.VerifyAccessToken(ctx, newTokenPair.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+		_, err = authService.RefreshAccessToken(ctx, newTokenPair.RefreshToken, client)
+		assert.Error(t, err)
+	})
+}
+
+func TestEmailVerification(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	mailer := &fakeMailer{}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, mailer, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+
+	ctx := context.Background()
+	user, _, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, models.NewClientInfo("test-agent", "127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("changing email sends a verification email", func(t *testing.T) {
+		user, err := authService.ChangeUserEmail(ctx, user.ID, dto.ChangeEmailReq{NewEmail: "first@example.com"})
+		assert.NoError(t, err)
+		assert.False(t, user.EmailVerified)
+		if assert.Len(t, mailer.sent, 1) {
+			assert.Equal(t, "first@example.com", mailer.sent[0].To)
+		}
+	})
+
+	t.Run("a token is invalid once the email changes", func(t *testing.T) {
+		staleToken := lastSentToken(t, mailer)
+
+		_, err := authService.ChangeUserEmail(ctx, user.ID, dto.ChangeEmailReq{NewEmail: "second@example.com"})
+		assert.NoError(t, err)
+
+		_, err = authService.VerifyEmail(ctx, dto.VerifyEmailReq{Token: staleToken})
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+	})
+
+	t.Run("verifying flips the flag and uses up the token", func(t *testing.T) {
+		token := lastSentToken(t, mailer)
+
+		verified, err := authService.VerifyEmail(ctx, dto.VerifyEmailReq{Token: token})
+		assert.NoError(t, err)
+		assert.True(t, verified.EmailVerified)
+
+		_, err = authService.VerifyEmail(ctx, dto.VerifyEmailReq{Token: token})
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+
+		err = authService.RequestEmailVerification(ctx, user.ID)
+		assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+	})
+
+	t.Run("tokens expire", func(t *testing.T) {
+		_, err := authService.ChangeUserEmail(ctx, user.ID, dto.ChangeEmailReq{NewEmail: "third@example.com"})
+		assert.NoError(t, err)
+
+		clock.Advance(models.EmailVerificationTokenTTL + time.Minute)
+
+		_, err = authService.VerifyEmail(ctx, dto.VerifyEmailReq{Token: lastSentToken(t, mailer)})
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	mailer := &fakeMailer{}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, mailer, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+
+	ctx := context.Background()
+	client := models.NewClientInfo("test-agent", "127.0.0.1")
+	user, tokenPair, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := authService.ChangeUserEmail(ctx, user.ID, dto.ChangeEmailReq{NewEmail: "test@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authService.VerifyEmail(ctx, dto.VerifyEmailReq{Token: lastSentToken(t, mailer)}); err != nil {
+		t.Fatal(err)
+	}
+	mailer.sent = nil
+
+	t.Run("unknown emails get the same response and no email", func(t *testing.T) {
+		err := authService.RequestPasswordReset(ctx, dto.RequestPasswordResetReq{Email: "nobody@example.com"}, client)
+		assert.NoError(t, err)
+		assert.Empty(t, mailer.sent)
+	})
+
+	t.Run("resetting changes the password and signs the user out", func(t *testing.T) {
+		err := authService.RequestPasswordReset(ctx, dto.RequestPasswordResetReq{Email: "test@example.com"}, client)
+		assert.NoError(t, err)
+		token := lastSentToken(t, mailer)
+
+		err = authService.ConfirmPasswordReset(ctx, dto.ConfirmPasswordResetReq{Token: token, NewPassword: "newpassword123"})
+		assert.NoError(t, err)
+
+		_, err = authService.RefreshAccessToken(ctx, tokenPair.RefreshToken, client)
+		assert.Error(t, err)
+
+		_, err = authService.VerifyAccessToken(ctx, tokenPair.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+		_, err = authService.Login(ctx, dto.UserLoginReq{Username: "testuser", Password: "newpassword123"}, client)
+		assert.NoError(t, err)
+
+		err = authService.ConfirmPasswordReset(ctx, dto.ConfirmPasswordResetReq{Token: token, NewPassword: "anotherpassword"})
+		assert.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+	})
+
+	t.Run("tokens expire", func(t *testing.T) {
+		err := authService.RequestPasswordReset(ctx, dto.RequestPasswordResetReq{Email: "test@example.com"}, client)
+		assert.NoError(t, err)
+
+		clock.Advance(models.PasswordResetTokenTTL + time.Minute)
+
+		err = authService.ConfirmPasswordReset(ctx, dto.ConfirmPasswordResetReq{Token: lastSentToken(t, mailer), NewPassword: "newpassword456"})
+		assert.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+	})
+
+	t.Run("requests are rate limited per account without saying so", func(t *testing.T) {
+		clock.Advance(models.PasswordResetRateLimitWindow)
+		mailer.sent = nil
+
+		for range models.PasswordResetMaxRequestsPerAccount + 2 {
+			err := authService.RequestPasswordReset(ctx, dto.RequestPasswordResetReq{Email: "test@example.com"}, models.NewClientInfo("test-agent", "10.0.0.1"))
+			assert.NoError(t, err)
+		}
+		assert.Len(t, mailer.sent, models.PasswordResetMaxRequestsPerAccount)
+	})
+
+	t.Run("requests are rate limited per IP", func(t *testing.T) {
+		clock.Advance(models.PasswordResetRateLimitWindow)
+		attacker := models.NewClientInfo("test-agent", "10.0.0.2")
+
+		for range models.PasswordResetMaxRequestsPerIPAddress {
+			err := authService.RequestPasswordReset(ctx, dto.RequestPasswordResetReq{Email: "nobody@example.com"}, attacker)
+			assert.NoError(t, err)
+		}
+
+		err := authService.RequestPasswordReset(ctx, dto.RequestPasswordResetReq{Email: "nobody@example.com"}, attacker)
+		var retryAfterErr *RetryAfterError
+		if assert.ErrorAs(t, err, &retryAfterErr) {
+			assert.ErrorIs(t, err, ErrTooManyRequests)
+			assert.Greater(t, retryAfterErr.RetryAfter, time.Duration(0))
+		}
+	})
+}
+
+func TestLoginThrottle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, &fakeMailer{}, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+
+	ctx := context.Background()
+	client := models.NewClientInfo("test-agent", "127.0.0.1")
+	if _, _, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, client); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongPassword := dto.UserLoginReq{Username: "testuser", Password: "wrongpassword"}
+	rightPassword := dto.UserLoginReq{Username: "testuser", Password: "password123"}
+
+	t.Run("failures past the free ones are delayed", func(t *testing.T) {
+		for range testLoginThrottle.Username.FreeFailures + 1 {
+			_, err := authService.Login(ctx, wrongPassword, client)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		_, err := authService.Login(ctx, rightPassword, client)
+		var retryAfterErr *RetryAfterError
+		if assert.ErrorAs(t, err, &retryAfterErr) {
+			assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+			assert.Equal(t, testLoginThrottle.Username.BaseDelay, retryAfterErr.RetryAfter)
+		}
+
+		clock.Advance(testLoginThrottle.Username.BaseDelay)
+		_, err = authService.Login(ctx, rightPassword, client)
+		assert.NoError(t, err, "expected a successful login once the delay has passed")
+	})
+
+	t.Run("enough failures lock the account out", func(t *testing.T) {
+		for range testLoginThrottle.Username.LockoutAfter {
+			_, err := authService.Login(ctx, wrongPassword, client)
+			var retryAfterErr *RetryAfterError
+			if errors.As(err, &retryAfterErr) {
+				clock.Advance(retryAfterErr.RetryAfter)
+				_, err = authService.Login(ctx, wrongPassword, client)
+			}
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		_, err := authService.Login(ctx, rightPassword, models.NewClientInfo("test-agent", "10.0.0.1"))
+		var retryAfterErr *RetryAfterError
+		if assert.ErrorAs(t, err, &retryAfterErr, "expected the lockout to apply from any IP address") {
+			assert.Equal(t, testLoginThrottle.Username.LockoutDuration, retryAfterErr.RetryAfter)
+		}
+	})
+
+	t.Run("unknown usernames are throttled too", func(t *testing.T) {
+		unknown := dto.UserLoginReq{Username: "nobody", Password: "password123"}
+		for range testLoginThrottle.Username.FreeFailures + 1 {
+			_, err := authService.Login(ctx, unknown, client)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+
+		_, err := authService.Login(ctx, unknown, client)
+		assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping auth service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	gracePeriod := 7 * 24 * time.Hour
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, &fakeMailer{}, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), gracePeriod)
+
+	ctx := context.Background()
+	client := models.NewClientInfo("test-agent", "127.0.0.1")
+	user, tokenPair, err := authService.Register(ctx, dto.UserRegisterReq{Username: "testuser", Password: "password123"}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("the password has to be confirmed", func(t *testing.T) {
+		_, err := authService.DeleteAccount(ctx, user.ID, dto.DeleteAccountReq{Password: "wrongpassword"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("deletion is scheduled and logs the user out", func(t *testing.T) {
+		deletionScheduledAt, err := authService.DeleteAccount(ctx, user.ID, dto.DeleteAccountReq{Password: "password123"})
+		assert.NoError(t, err)
+		if assert.NotNil(t, deletionScheduledAt) {
+			assert.WithinDuration(t, clock.Now().Add(gracePeriod), *deletionScheduledAt, time.Second)
+		}
+
+		_, err = authService.VerifyAccessToken(ctx, tokenPair.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+	})
+
+	t.Run("logging back in cancels the deletion", func(t *testing.T) {
+		_, err := authService.Login(ctx, dto.UserLoginReq{Username: "testuser", Password: "password123"}, client)
+		assert.NoError(t, err)
+
+		clock.Advance(gracePeriod + time.Minute)
+		deleted, err := authService.DeleteScheduledAccounts(ctx, 10)
+		assert.NoError(t, err)
+		assert.Zero(t, deleted)
+	})
+
+	t.Run("the account is deleted once the grace period passes", func(t *testing.T) {
+		_, err := authService.DeleteAccount(ctx, user.ID, dto.DeleteAccountReq{Password: "password123"})
+		assert.NoError(t, err)
+
+		deleted, err := authService.DeleteScheduledAccounts(ctx, 10)
+		assert.NoError(t, err)
+		assert.Zero(t, deleted, "expected the account to be kept during the grace period")
+
+		clock.Advance(gracePeriod)
+		deleted, err = authService.DeleteScheduledAccounts(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+
+		_, err = store.User.GetByID(ctx, user.ID)
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+	})
+}
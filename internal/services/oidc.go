@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/oidc"
+	"github.com/jasonuc/moota/internal/store"
+)
+
+var (
+	ErrUnknownOIDCProvider    = errors.New("unknown oidc provider")
+	ErrInvalidOIDCLogin       = errors.New("invalid or expired oidc login")
+	ErrInvalidOIDCSignupToken = errors.New("invalid or expired oidc signup token")
+	ErrIdentityAlreadyLinked  = errors.New("this account is already linked to a user")
+	ErrProviderAlreadyLinked  = errors.New("an account from this provider is already linked")
+	ErrLastLoginMethod        = errors.New("cannot unlink the only way to log in, set a password first")
+	ErrProviderNotLinked      = errors.New("no account from this provider is linked")
+	ErrReauthenticationFailed = errors.New("log in again with the account from the provider that is linked")
+)
+
+// OIDCCallbackResult is what came of a user returning from a provider. Exactly one of TokenPair (logged in),
+// Linked (identity added to a logged in user), Signup (a username needs picking) or PasswordSetToken
+// (a password reset token for a user without a password who logged in again) is set.
+type OIDCCallbackResult struct {
+	TokenPair        *models.TokenPair
+	Linked           bool
+	Signup           *OIDCPendingSignup
+	PasswordSetToken string
+}
+
+// OIDCPendingSignup is handed to a new user so they can pick a username to finish signing up with.
+type OIDCPendingSignup struct {
+	Token             string `json:"token"`
+	SuggestedUsername string `json:"suggestedUsername"`
+}
+
+type OIDCService interface {
+	Providers() []string
+	StartLogin(context.Context, string, *string) (string, string, error)
+	StartSetPassword(context.Context, string, string) (string, string, error)
+	HandleCallback(context.Context, string, string, string, models.ClientInfo) (*OIDCCallbackResult, error)
+	CompleteSignup(context.Context, dto.OIDCSignupReq, models.ClientInfo) (*models.User, *models.TokenPair, error)
+	GetIdentities(context.Context, string) ([]*models.Identity, error)
+	UnlinkIdentity(context.Context, string, string) error
+}
+
+type oidcService struct {
+	store       *store.Store
+	authService AuthService
+	providers   map[string]oidc.Provider
+	clock       models.Clock
+
+	contentFilter moderation.ContentFilter
+}
+
+func NewOIDCService(store *store.Store, authService AuthService, providers []oidc.Provider, clock models.Clock, contentFilter moderation.ContentFilter) OIDCService {
+	providersByName := make(map[string]oidc.Provider, len(providers))
+	for _, provider := range providers {
+		providersByName[provider.Name()] = provider
+	}
+
+	return &oidcService{
+		store:       store,
+		authService: authService,
+		providers:   providersByName,
+		clock:       clock,
+
+		contentFilter: contentFilter,
+	}
+}
+
+// Providers are the names of the providers users can log in with.
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// StartLogin returns where to send the user to log in with the provider and the state the provider will send back.
+// When linkUserID is set the provider account is linked to that user instead of being logged in with.
+func (s *oidcService) StartLogin(ctx context.Context, providerName string, linkUserID *string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownOIDCProvider
+	}
+
+	return s.startLogin(ctx, provider, &models.OIDCLoginState{LinkUserID: linkUserID}, false)
+}
+
+// StartSetPassword returns where to send a user without a password so they can log in with a provider they have
+// linked again. The provider is asked for a fresh login, and once it is done the user gets a token to set a password with.
+func (s *oidcService) StartSetPassword(ctx context.Context, providerName, userID string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownOIDCProvider
+	}
+
+	user, err := s.store.User.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(user.PasswordHash) != 0 {
+		return "", "", ErrPasswordAlreadySet
+	}
+
+	identities, err := s.store.Identity.GetByUserID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !slices.ContainsFunc(identities, func(identity *models.Identity) bool { return identity.Provider == providerName }) {
+		return "", "", ErrProviderNotLinked
+	}
+
+	return s.startLogin(ctx, provider, &models.OIDCLoginState{SetPasswordUserID: &userID}, true)
+}
+
+// startLogin fills in the rest of the login state, saves it and returns where to send the user along with the state.
+func (s *oidcService) startLogin(ctx context.Context, provider oidc.Provider, loginState *models.OIDCLoginState, reauthenticate bool) (string, string, error) {
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	codeVerifier, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := s.clock.Now()
+	loginState.State = state
+	loginState.Provider = provider.Name()
+	loginState.Nonce = nonce
+	loginState.CodeVerifier = codeVerifier
+	loginState.CreatedAt = now
+	loginState.ExpiresAt = now.Add(models.OIDCLoginStateTTL)
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(codeVerifier), reauthenticate)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.store.Identity.InsertLoginState(ctx, loginState); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// HandleCallback finishes a login the provider has sent the user back from. A known identity is logged in,
+// a linking user gets the identity added, and anyone else is given a signup token to pick a username with.
+// Accounts are never linked by matching emails, since that would hand an account to whoever controls the email at the provider.
+func (s *oidcService) HandleCallback(ctx context.Context, providerName, state, code string, client models.ClientInfo) (*OIDCCallbackResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	loginState, err := s.store.Identity.TakeLoginState(ctx, state)
+	if err != nil {
+		if errors.Is(err, models.ErrOIDCLoginStateNotFound) {
+			return nil, ErrInvalidOIDCLogin
+		}
+		return nil, err
+	}
+
+	if loginState.Provider != providerName || loginState.ExpiresAt.Before(s.clock.Now()) {
+		return nil, ErrInvalidOIDCLogin
+	}
+
+	claims, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOIDCLogin, err)
+	}
+
+	if loginState.LinkUserID != nil {
+		if err := s.linkIdentity(ctx, *loginState.LinkUserID, providerName, claims); err != nil {
+			return nil, err
+		}
+		return &OIDCCallbackResult{Linked: true}, nil
+	}
+
+	if loginState.SetPasswordUserID != nil {
+		token, err := s.issuePasswordSetToken(ctx, *loginState.SetPasswordUserID, loginState, claims)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCCallbackResult{PasswordSetToken: token}, nil
+	}
+
+	identity, err := s.store.Identity.GetByProviderSubject(ctx, providerName, claims.Subject)
+	if err != nil {
+		if errors.Is(err, models.ErrIdentityNotFound) {
+			signup, err := s.startSignup(ctx, providerName, claims)
+			if err != nil {
+				return nil, err
+			}
+			return &OIDCCallbackResult{Signup: signup}, nil
+		}
+		return nil, err
+	}
+
+	user, err := s.store.User.GetByID(ctx, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Identity.UpdateLastLogin(ctx, identity.ID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	tokenPair, err := s.authService.StartSession(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCCallbackResult{TokenPair: tokenPair}, nil
+}
+
+// issuePasswordSetToken checks the user logged in again with an identity of theirs, and that the provider made them
+// actually log in after the set password login was started rather than reusing a session it already had.
+func (s *oidcService) issuePasswordSetToken(ctx context.Context, userID string, loginState *models.OIDCLoginState, claims *oidc.Claims) (string, error) {
+	identity, err := s.store.Identity.GetByProviderSubject(ctx, loginState.Provider, claims.Subject)
+	if err != nil {
+		if errors.Is(err, models.ErrIdentityNotFound) {
+			return "", ErrReauthenticationFailed
+		}
+		return "", err
+	}
+
+	if identity.UserID != userID {
+		return "", ErrReauthenticationFailed
+	}
+
+	if claims.AuthTime.IsZero() || claims.AuthTime.Before(loginState.CreatedAt.Add(-models.OIDCAuthTimeLeeway)) {
+		return "", ErrReauthenticationFailed
+	}
+
+	if err := s.store.Identity.UpdateLastLogin(ctx, identity.ID, s.clock.Now()); err != nil {
+		return "", err
+	}
+
+	return s.authService.IssuePasswordSetToken(ctx, userID)
+}
+
+func (s *oidcService) linkIdentity(ctx context.Context, userID, providerName string, claims *oidc.Claims) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	existing, err := tx.Identity.GetByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		if existing.UserID == userID {
+			return nil
+		}
+		return ErrIdentityAlreadyLinked
+	}
+	if !errors.Is(err, models.ErrIdentityNotFound) {
+		return err
+	}
+
+	identities, err := tx.Identity.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, identity := range identities {
+		if identity.Provider == providerName {
+			return ErrProviderAlreadyLinked
+		}
+	}
+
+	identity := &models.Identity{
+		UserID:    userID,
+		Provider:  providerName,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := tx.Identity.Insert(ctx, identity); err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+func (s *oidcService) startSignup(ctx context.Context, providerName string, claims *oidc.Claims) (*OIDCPendingSignup, error) {
+	plain, hash, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	signup := &models.OIDCSignup{
+		Hash:              hash,
+		Plain:             plain,
+		Provider:          providerName,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		SuggestedUsername: models.SuggestUsername(claims.PreferredUsername, claims.Email),
+		CreatedAt:         now,
+		ExpiresAt:         now.Add(models.OIDCSignupTTL),
+	}
+
+	if err := s.store.Identity.InsertSignup(ctx, signup); err != nil {
+		return nil, err
+	}
+
+	return &OIDCPendingSignup{
+		Token:             signup.Plain,
+		SuggestedUsername: signup.SuggestedUsername,
+	}, nil
+}
+
+// CompleteSignup creates the user for a provider login once they have picked a username. The user has no password,
+// and takes the provider's email only if the provider has verified it and no one else is using it.
+func (s *oidcService) CompleteSignup(ctx context.Context, dto dto.OIDCSignupReq, client models.ClientInfo) (*models.User, *models.TokenPair, error) {
+	if err := isValidUsername(dto.Username); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.contentFilter.Check(moderation.FieldUsername, dto.Username); err != nil {
+		return nil, nil, err
+	}
+
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	tokenHash := sha256.Sum256([]byte(dto.Token))
+	signup, err := tx.Identity.TakeSignup(ctx, tokenHash[:])
+	if err != nil {
+		if errors.Is(err, models.ErrOIDCSignupNotFound) {
+			return nil, nil, ErrInvalidOIDCSignupToken
+		}
+		return nil, nil, err
+	}
+
+	now := s.clock.Now()
+	if signup.ExpiresAt.Before(now) {
+		return nil, nil, ErrInvalidOIDCSignupToken
+	}
+
+	if _, err := tx.User.GetByUsername(ctx, dto.Username); err == nil {
+		return nil, nil, ErrUsernameTaken
+	}
+
+	// the identity may have been linked to someone while the username was being picked
+	if _, err := tx.Identity.GetByProviderSubject(ctx, signup.Provider, signup.Subject); err == nil {
+		return nil, nil, ErrIdentityAlreadyLinked
+	}
+
+	user := &models.User{
+		Username:  dto.Username,
+		LevelMeta: models.NewLeveLMeta(1, 0),
+	}
+	if signup.Email != "" && signup.EmailVerified {
+		if _, err := tx.User.GetByEmail(ctx, signup.Email); errors.Is(err, models.ErrUserNotFound) {
+			user.Email = signup.Email
+		}
+	}
+
+	if err := tx.User.Insert(ctx, user); err != nil {
+		return nil, nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if user.Email != "" {
+		user.EmailVerified = true
+		if err := tx.User.Update(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	identity := &models.Identity{
+		UserID:      user.ID,
+		Provider:    signup.Provider,
+		Subject:     signup.Subject,
+		Email:       signup.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	}
+	if err := tx.Identity.Insert(ctx, identity); err != nil {
+		return nil, nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	tokenPair, err := s.authService.StartSession(ctx, user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokenPair, nil
+}
+
+func (s *oidcService) GetIdentities(ctx context.Context, userID string) ([]*models.Identity, error) {
+	return s.store.Identity.GetByUserID(ctx, userID)
+}
+
+// UnlinkIdentity removes one of the user's identities, unless it is the only way they have left to log in.
+func (s *oidcService) UnlinkIdentity(ctx context.Context, userID, identityID string) error {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+	tx := s.store.WithTx(transaction)
+
+	user, err := tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	identities, err := tx.Identity.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(identities, func(identity *models.Identity) bool { return identity.ID == identityID }) {
+		return models.ErrIdentityNotFound
+	}
+
+	if len(user.PasswordHash) == 0 && len(identities) == 1 {
+		return ErrLastLoginMethod
+	}
+
+	if err := tx.Identity.Delete(ctx, userID, identityID); err != nil {
+		return err
+	}
+
+	return transaction.Commit()
+}
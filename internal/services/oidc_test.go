@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/dto"
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/jasonuc/moota/internal/oidc"
+	"github.com/jasonuc/moota/internal/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider logs in whoever the code it is given belongs to.
+type fakeProvider struct {
+	users map[string]*oidc.Claims
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string, reauthenticate bool) (string, error) {
+	return "https://provider.test/authorize?state=" + state, nil
+}
+
+func (p *fakeProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Claims, error) {
+	claims, ok := p.users[code]
+	if !ok {
+		return nil, oidc.ErrExchangeFailed
+	}
+	return claims, nil
+}
+
+func TestOIDCLogin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping oidc service integration tests")
+	}
+
+	store := newTestStore(t, "auth_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	authService := NewAuthService(store, signing.NewHMACKeySet([]byte("secret")), 7*24*time.Hour, time.Hour, "moota", clock, &fakeMailer{}, testEmailLinks, testLoginThrottle, moderation.NewDefaultFilter(), 0)
+	provider := &fakeProvider{users: map[string]*oidc.Claims{
+		"alice": {Subject: "alice-sub", Email: "alice@example.com", EmailVerified: true, PreferredUsername: "Alice_99"},
+		"bob":   {Subject: "bob-sub", Email: "bob@example.com"},
+	}}
+	oidcService := NewOIDCService(store, authService, []oidc.Provider{provider}, clock, moderation.NewDefaultFilter())
+
+	ctx := context.Background()
+	client := models.NewClientInfo("test-agent", "127.0.0.1")
+
+	login := func(code string, linkUserID *string) (*OIDCCallbackResult, error) {
+		_, state, err := oidcService.StartLogin(ctx, "fake", linkUserID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return oidcService.HandleCallback(ctx, "fake", state, code, client)
+	}
+
+	var aliceID string
+
+	t.Run("a new identity has to pick a username", func(t *testing.T) {
+		result, err := login("alice", nil)
+		assert.NoError(t, err)
+		assert.Nil(t, result.TokenPair)
+		assert.Equal(t, "alice", result.Signup.SuggestedUsername)
+
+		user, tokenPair, err := oidcService.CompleteSignup(ctx, dto.OIDCSignupReq{Token: result.Signup.Token, Username: "alice"}, client)
+		assert.NoError(t, err)
+		assert.NotNil(t, tokenPair)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.True(t, user.EmailVerified)
+		aliceID = user.ID
+
+		_, _, err = oidcService.CompleteSignup(ctx, dto.OIDCSignupReq{Token: result.Signup.Token, Username: "alicetwo"}, client)
+		assert.ErrorIs(t, err, ErrInvalidOIDCSignupToken)
+	})
+
+	t.Run("a known identity is logged in", func(t *testing.T) {
+		result, err := login("alice", nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, result.TokenPair)
+
+		userID, err := authService.VerifyAccessToken(ctx, result.TokenPair.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, aliceID, userID)
+	})
+
+	t.Run("a state can only be used once", func(t *testing.T) {
+		_, state, err := oidcService.StartLogin(ctx, "fake", nil)
+		assert.NoError(t, err)
+
+		_, err = oidcService.HandleCallback(ctx, "fake", state, "alice", client)
+		assert.NoError(t, err)
+
+		_, err = oidcService.HandleCallback(ctx, "fake", state, "alice", client)
+		assert.ErrorIs(t, err, ErrInvalidOIDCLogin)
+	})
+
+	t.Run("an expired login is refused", func(t *testing.T) {
+		_, state, err := oidcService.StartLogin(ctx, "fake", nil)
+		assert.NoError(t, err)
+
+		clock.Advance(models.OIDCLoginStateTTL + time.Second)
+		_, err = oidcService.HandleCallback(ctx, "fake", state, "alice", client)
+		assert.ErrorIs(t, err, ErrInvalidOIDCLogin)
+	})
+
+	t.Run("an existing user can link an identity", func(t *testing.T) {
+		user, _, err := authService.Register(ctx, dto.UserRegisterReq{Username: "bobby", Password: "password123"}, client)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := login("bob", &user.ID)
+		assert.NoError(t, err)
+		assert.True(t, result.Linked)
+
+		result, err = login("bob", nil)
+		assert.NoError(t, err)
+		assert.NotNil(t, result.TokenPair)
+
+		_, err = login("alice", &user.ID)
+		assert.ErrorIs(t, err, ErrIdentityAlreadyLinked)
+
+		identities, err := oidcService.GetIdentities(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, identities, 1)
+
+		assert.NoError(t, oidcService.UnlinkIdentity(ctx, user.ID, identities[0].ID), "expected a user with a password to be able to unlink")
+	})
+
+	t.Run("the only way to log in cannot be unlinked", func(t *testing.T) {
+		identities, err := oidcService.GetIdentities(ctx, aliceID)
+		assert.NoError(t, err)
+		assert.Len(t, identities, 1)
+
+		err = oidcService.UnlinkIdentity(ctx, aliceID, identities[0].ID)
+		assert.ErrorIs(t, err, ErrLastLoginMethod)
+	})
+
+	t.Run("a user without a password cannot change it", func(t *testing.T) {
+		_, _, err := authService.ChangeUserPassword(ctx, aliceID, dto.ChangePasswordReq{OldPassword: "anything", NewPassword: "password456"}, client)
+		assert.ErrorIs(t, err, ErrNoPasswordSet)
+	})
+
+	t.Run("a user without a password can set one after logging in again", func(t *testing.T) {
+		setPassword := func(code string) (*OIDCCallbackResult, error) {
+			_, state, err := oidcService.StartSetPassword(ctx, "fake", aliceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return oidcService.HandleCallback(ctx, "fake", state, code, client)
+		}
+
+		provider.users["alice"].AuthTime = clock.Now().Add(-time.Hour)
+		_, err := setPassword("alice")
+		assert.ErrorIs(t, err, ErrReauthenticationFailed, "expected a login the provider didn't make fresh to be refused")
+
+		provider.users["bob"].AuthTime = clock.Now()
+		_, err = setPassword("bob")
+		assert.ErrorIs(t, err, ErrReauthenticationFailed, "expected someone else's identity to be refused")
+
+		provider.users["alice"].AuthTime = clock.Now()
+		result, err := setPassword("alice")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NotEmpty(t, result.PasswordSetToken)
+
+		err = authService.ConfirmPasswordReset(ctx, dto.ConfirmPasswordResetReq{Token: result.PasswordSetToken, NewPassword: "password123"})
+		assert.NoError(t, err)
+
+		_, err = authService.Login(ctx, dto.UserLoginReq{Username: "alice", Password: "password123"}, client)
+		assert.NoError(t, err)
+
+		_, _, err = oidcService.StartSetPassword(ctx, "fake", aliceID)
+		assert.ErrorIs(t, err, ErrPasswordAlreadySet)
+	})
+}
@@ -3,32 +3,43 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jasonuc/moota/internal/contextkeys"
 	"github.com/jasonuc/moota/internal/dto"
 	"github.com/jasonuc/moota/internal/models"
+	"github.com/jasonuc/moota/internal/moderation"
 	"github.com/jasonuc/moota/internal/store"
 )
 
 type PlantService interface {
 	GetUserPlants(context.Context, string, *models.Coordinates, *store.GetPlantsOpts) ([]*models.PlantWithDistanceMFromUser, error)
-	ActionOnPlant(context.Context, string, dto.ActionOnPlantReq) (*models.Plant, error)
+	ActionOnPlant(context.Context, string, dto.ActionOnPlantReq) (*models.Plant, *models.PlayerProgress, error)
 	GetPlant(context.Context, string) (*models.Plant, error)
+	GetNearbyPlants(context.Context, models.Coordinates, float64, int, int) ([]*models.NearbyPlant, bool, error)
+	GetPlantHistory(context.Context, string, int, int) ([]*models.PlantEvent, bool, error)
 	CreatePlant(context.Context, *models.Soil, *models.Seed, models.Coordinates) (*models.Plant, error)
 	GetUserDeceasedPlants(context.Context, string) ([]*models.Plant, error)
 	ChangePlantNickname(context.Context, string, string) (*models.Plant, error)
+	ChangePlantCarePermission(context.Context, string, dto.ChangePlantCarePermissionReq) (*models.Plant, error)
+	ChangePlantLocationSharing(context.Context, string, dto.ChangePlantLocationSharingReq) (*models.Plant, error)
 	KillPlant(context.Context, string) error
+	RefreshStalePlants(context.Context, time.Duration, int) (int, error)
 	WithStore(*store.Store) PlantService
 }
 
 type plantService struct {
-	store *store.Store
+	store         *store.Store
+	clock         models.Clock
+	contentFilter moderation.ContentFilter
 }
 
-func NewPlantService(store *store.Store) PlantService {
+func NewPlantService(store *store.Store, clock models.Clock, contentFilter moderation.ContentFilter) PlantService {
 	return &plantService{
-		store: store,
+		store:         store,
+		clock:         clock,
+		contentFilter: contentFilter,
 	}
 }
 
@@ -44,6 +55,21 @@ var (
 	ErrInvalidPlantAction            = errors.New("invalid plant action")
 	ErrUnauthorisedPlantAction       = errors.New("unauthorised plant action")
 	ErrPlantAlreadyDead              = errors.New("plant already dead")
+	ErrInvalidPagination             = errors.New("invalid pagination")
+	ErrCommunityWateringCapReached   = errors.New("daily limit for watering other players' plants reached")
+	ErrUnknownHelper                 = errors.New("helper not found")
+	ErrInvalidCoordinates            = errors.New("invalid coordinates")
+	ErrInvalidNearbyRadius           = fmt.Errorf("radius must be between 1 and %d metres", NearbyPlantsMaxRadiusM)
+)
+
+const (
+	PlantHistoryDefaultPageSize = 20
+	PlantHistoryMaxPageSize     = 100
+
+	NearbyPlantsDefaultRadiusM  = 1000
+	NearbyPlantsMaxRadiusM      = 5000
+	NearbyPlantsDefaultPageSize = 20
+	NearbyPlantsMaxPageSize     = 50
 )
 
 func (s *plantService) GetUserPlants(ctx context.Context, userID string, dto *models.Coordinates, opts *store.GetPlantsOpts) ([]*models.PlantWithDistanceMFromUser, error) {
@@ -58,12 +84,12 @@ func (s *plantService) GetUserPlants(ctx context.Context, userID string, dto *mo
 
 	tx := s.store.WithTx(transaction)
 
-	plants, err := tx.Plant.GetByOwnerID(ctx, userID, opts)
+	plants, err := tx.Plant.GetByOwnerID(ctx, userID, &store.GetPlantsOpts{IncludeDeceased: opts.IncludeDeceased, ForUpdate: true})
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	err = refreshPlantsData(ctx, tx, plants, now)
 	if err != nil {
 		return nil, err
@@ -94,12 +120,12 @@ func (s *plantService) GetPlant(ctx context.Context, plantID string) (*models.Pl
 
 	tx := s.store.WithTx(transaction)
 
-	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{})
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{ForUpdate: true})
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	err = refreshPlantData(ctx, tx, plant, now)
 	if err != nil {
 		return nil, err
@@ -112,9 +138,57 @@ func (s *plantService) GetPlant(ctx context.Context, plantID string) (*models.Pl
 	return plant, nil
 }
 
+// GetNearbyPlants returns a page of other players' living plants shown within radiusM of coords, nearest first.
+func (s *plantService) GetNearbyPlants(ctx context.Context, coords models.Coordinates, radiusM float64, page, pageSize int) ([]*models.NearbyPlant, bool, error) {
+	userID, err := contextkeys.GetUserIDFromCtx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// written this way round so NaN is rejected too
+	if !(coords.Lat >= -90 && coords.Lat <= 90 && coords.Lon >= -180 && coords.Lon <= 180) {
+		return nil, false, ErrInvalidCoordinates
+	}
+
+	if radiusM < 1 || radiusM > NearbyPlantsMaxRadiusM {
+		return nil, false, ErrInvalidNearbyRadius
+	}
+
+	if page < 1 || pageSize < 1 || pageSize > NearbyPlantsMaxPageSize {
+		return nil, false, ErrInvalidPagination
+	}
+
+	// one more than a page is fetched to know if there is another page
+	plants, err := s.store.Plant.GetNearby(ctx, userID, coords, radiusM, pageSize+1, (page-1)*pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(plants) > pageSize
+	if hasMore {
+		plants = plants[:pageSize]
+	}
+
+	// the plants belong to other players, so they are only brought up to date for the response.
+	// saving them is left to the refresh worker and their owners' own requests
+	now := s.clock.Now()
+	for _, p := range plants {
+		var neighbours []*models.Plant
+		if p.DueForRefresh(now) {
+			neighbours, err = getPlantNeighbours(ctx, s.store, &p.Plant)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		p.Refresh(now, neighbours...)
+	}
+
+	return plants, hasMore, nil
+}
+
 func (s *plantService) CreatePlant(ctx context.Context, soil *models.Soil, seed *models.Seed, centre models.Coordinates) (*models.Plant, error) {
-	plantCircleMeta := models.NewCircleMeta(soil.Centre(), models.PlantInteractionRadius)
-	nearbyPlants, err := s.store.Plant.GetBySoilIDAndProximity(ctx, soil.ID, centre, models.PlantInteractionRadius+0.1)
+	plantCircleMeta := models.NewCircleMeta(centre, models.PlantInteractionRadius)
+	nearbyPlants, err := s.store.Plant.GetBySoilIDAndProximity(ctx, soil.ID, centre, models.PlantSpacingM)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +196,7 @@ func (s *plantService) CreatePlant(ctx context.Context, soil *models.Soil, seed
 		return nil, ErrNotPossibleToCreatePlant
 	}
 
-	plant, err := models.NewPlant(seed, soil, centre)
+	plant, err := models.NewPlant(seed, soil, centre, s.clock.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -135,55 +209,163 @@ func (s *plantService) CreatePlant(ctx context.Context, soil *models.Soil, seed
 	return plant, nil
 }
 
-func (s *plantService) ActionOnPlant(ctx context.Context, plantID string, dto dto.ActionOnPlantReq) (*models.Plant, error) {
+func (s *plantService) ActionOnPlant(ctx context.Context, plantID string, dto dto.ActionOnPlantReq) (*models.Plant, *models.PlayerProgress, error) {
 	userID, err := contextkeys.GetUserIDFromCtx(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if !models.ValidPlantAction(dto.Action) {
-		return nil, ErrInvalidPlantAction
+		return nil, nil, ErrInvalidPlantAction
 	}
 
 	transaction, err := s.store.Begin()
 	if err != nil {
-		return nil, store.ErrTransactionCouldNotStart
+		return nil, nil, store.ErrTransactionCouldNotStart
 	}
 	//nolint:errcheck
 	defer transaction.Rollback()
 
 	tx := s.store.WithTx(transaction)
 
-	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{})
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{ForUpdate: true})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if plant.OwnerID != userID {
-		return nil, ErrUnauthorisedPlantAction
+	now := s.clock.Now()
+	action := models.PlantAction(dto.Action)
+	isOwner := plant.OwnerID == userID
+
+	if !isOwner {
+		if err := checkCommunityWatering(ctx, tx, plant, userID, action, now); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	userCoords := models.Coordinates{Lon: *dto.Longitude, Lat: *dto.Latitude}
 	if !plant.ContainsPoint(userCoords) {
-		return nil, ErrOutsidePlantInteractionRadius
+		return nil, nil, ErrOutsidePlantInteractionRadius
 	}
 
-	now := time.Now()
-	_, err = plant.Action(models.PlantAction(dto.Action), now)
+	neighbours, err := getPlantNeighbours(ctx, tx, plant)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	previousLevel := plant.Level
+	lastCalculatedAt := plant.LastCalculatedAt()
+
+	alive, err := plant.Action(action, now, neighbours...)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	err = tx.Plant.Update(ctx, plant)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	events := plant.PopEvents()
+	for _, event := range events {
+		if event.Action != 0 {
+			event.ActorID = &userID
+			event.Location = &userCoords
+		}
+	}
+
+	if err := insertPlantEvents(ctx, tx, events); err != nil {
+		return nil, nil, err
+	}
+
+	ownerXp := models.PlayerXpForSurvivalMilestones(plant, lastCalculatedAt, plant.LastCalculatedAt())
+	if alive {
+		ownerXp += models.PlayerXpForPlantLevelUps(previousLevel, plant.Level)
+	}
+
+	var progress *models.PlayerProgress
+	if isOwner {
+		if alive {
+			ownerXp += models.PlayerXpForPlantAction(action)
+		}
+
+		if alive && action == models.PlantActionWater {
+			if err := tx.Achievement.IncrementWaterings(ctx, userID); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		progress, err = awardPlayerXp(ctx, tx, plant.OwnerID, ownerXp)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// the owner still gets the XP their plant earned, the helper is credited for the watering
+		if ownerXp > 0 {
+			if _, err := awardPlayerXp(ctx, tx, plant.OwnerID, ownerXp); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var helperXp int64
+		if alive {
+			helperXp = models.PlayerXpForCommunityWatering()
+			if err := tx.Achievement.IncrementCommunityWaterings(ctx, userID); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		progress, err = awardPlayerXp(ctx, tx, userID, helperXp)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	progress.NewAchievements, err = evaluateAchievements(ctx, tx, userID, now)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if err := transaction.Commit(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return plant, nil
+	return plant, progress, nil
+}
+
+// GetPlantHistory returns a page of the plant's events, most recent first, and whether there are older events.
+// Only the plant's owner can see its history.
+func (s *plantService) GetPlantHistory(ctx context.Context, plantID string, page, pageSize int) ([]*models.PlantEvent, bool, error) {
+	userID, err := contextkeys.GetUserIDFromCtx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if page < 1 || pageSize < 1 || pageSize > PlantHistoryMaxPageSize {
+		return nil, false, ErrInvalidPagination
+	}
+
+	plant, err := s.store.Plant.Get(ctx, plantID, &store.GetPlantsOpts{IncludeDeceased: true})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if plant.OwnerID != userID {
+		return nil, false, ErrUnauthorisedPlantAction
+	}
+
+	// one extra event is fetched to tell whether there is another page
+	events, err := s.store.PlantEvent.GetByPlantID(ctx, plantID, pageSize+1, (page-1)*pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(events) > pageSize
+	if hasMore {
+		events = events[:pageSize]
+	}
+
+	return events, hasMore, nil
 }
 
 func (s *plantService) GetUserDeceasedPlants(ctx context.Context, userID string) ([]*models.Plant, error) {
@@ -192,7 +374,7 @@ func (s *plantService) GetUserDeceasedPlants(ctx context.Context, userID string)
 		return nil, err
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	for _, plant := range userPlants {
 		plant.Refresh(now)
 	}
@@ -213,6 +395,10 @@ func (s *plantService) ChangePlantNickname(ctx context.Context, plantID string,
 		return nil, err
 	}
 
+	if err := s.contentFilter.Check(moderation.FieldNickname, newNickname); err != nil {
+		return nil, err
+	}
+
 	transaction, err := s.store.Begin()
 	if err != nil {
 		return nil, store.ErrTransactionCouldNotStart
@@ -222,12 +408,12 @@ func (s *plantService) ChangePlantNickname(ctx context.Context, plantID string,
 
 	tx := s.store.WithTx(transaction)
 
-	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{})
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{ForUpdate: true})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := refreshPlantData(ctx, tx, plant, time.Now()); err != nil {
+	if err := refreshPlantData(ctx, tx, plant, s.clock.Now()); err != nil {
 		return nil, err
 	}
 
@@ -247,6 +433,107 @@ func (s *plantService) ChangePlantNickname(ctx context.Context, plantID string,
 	return plant, nil
 }
 
+// ChangePlantCarePermission sets who besides the owner may water the plant.
+// When helper usernames are given they replace the plant's current helpers.
+func (s *plantService) ChangePlantCarePermission(ctx context.Context, plantID string, dto dto.ChangePlantCarePermissionReq) (*models.Plant, error) {
+	userID, err := contextkeys.GetUserIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permission, err := models.ParseCarePermission(dto.Permission)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{ForUpdate: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if plant.OwnerID != userID {
+		return nil, ErrUnauthorisedPlantAction
+	}
+
+	if dto.HelperUsernames != nil {
+		helperIDs := make([]string, 0, len(dto.HelperUsernames))
+		for _, username := range dto.HelperUsernames {
+			helper, err := tx.User.GetByUsername(ctx, username)
+			if err != nil {
+				if errors.Is(err, models.ErrUserNotFound) {
+					return nil, ErrUnknownHelper
+				}
+				return nil, err
+			}
+
+			if helper.ID != userID {
+				helperIDs = append(helperIDs, helper.ID)
+			}
+		}
+
+		if err := tx.Plant.SetHelpers(ctx, plant.ID, helperIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	plant.CarePermission = permission
+	if err := refreshPlantData(ctx, tx, plant, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return plant, nil
+}
+
+// ChangePlantLocationSharing sets whether other players are shown exactly where the plant is or only roughly.
+func (s *plantService) ChangePlantLocationSharing(ctx context.Context, plantID string, dto dto.ChangePlantLocationSharingReq) (*models.Plant, error) {
+	userID, err := contextkeys.GetUserIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	plant, err := tx.Plant.Get(ctx, plantID, &store.GetPlantsOpts{ForUpdate: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if plant.OwnerID != userID {
+		return nil, ErrUnauthorisedPlantAction
+	}
+
+	plant.ShareExactLocation = *dto.ShareExactLocation
+	if err := refreshPlantData(ctx, tx, plant, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return plant, nil
+}
+
 func (s *plantService) KillPlant(ctx context.Context, id string) error {
 	userIDFromCtx, err := contextkeys.GetUserIDFromCtx(ctx)
 	if err != nil {
@@ -262,7 +549,7 @@ func (s *plantService) KillPlant(ctx context.Context, id string) error {
 
 	tx := s.store.WithTx(transaction)
 
-	plant, err := tx.Plant.Get(ctx, id, &store.GetPlantsOpts{})
+	plant, err := tx.Plant.Get(ctx, id, &store.GetPlantsOpts{ForUpdate: true})
 	if err != nil {
 		return err
 	}
@@ -275,11 +562,20 @@ func (s *plantService) KillPlant(ctx context.Context, id string) error {
 		return ErrPlantAlreadyDead
 	}
 
-	plant.Die(time.Now())
+	plant.Die(s.clock.Now())
 	if err := tx.Plant.Update(ctx, plant); err != nil {
 		return err
 	}
 
+	events := plant.PopEvents()
+	for _, event := range events {
+		event.ActorID = &userIDFromCtx
+	}
+
+	if err := insertPlantEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
 	if err := transaction.Commit(); err != nil {
 		return err
 	}
@@ -287,6 +583,39 @@ func (s *plantService) KillPlant(ctx context.Context, id string) error {
 	return nil
 }
 
+// RefreshStalePlants applies the time based changes to one batch of living plants that have not been refreshed for staleAfter,
+// so neglected plants decay and die without their owner opening the app. It returns how many plants were refreshed.
+func (s *plantService) RefreshStalePlants(ctx context.Context, staleAfter time.Duration, batchSize int) (int, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return 0, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	now := s.clock.Now()
+	// refreshing a plant less than a decay interval after it was last calculated would not move it forward,
+	// and the worker would keep picking the same plants up again
+	staleBefore := now.Add(-max(staleAfter, models.HpDecayInterval))
+
+	plants, err := tx.Plant.GetStaleForRefresh(ctx, staleBefore, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := refreshPlantsData(ctx, tx, plants, now); err != nil {
+		return 0, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(plants), nil
+}
+
 func refreshPlantsData(ctx context.Context, tx *store.Store, plants []*models.Plant, t time.Time) error {
 	for _, plant := range plants {
 		err := refreshPlantData(ctx, tx, plant, t)
@@ -298,8 +627,95 @@ func refreshPlantsData(ctx context.Context, tx *store.Store, plants []*models.Pl
 }
 
 func refreshPlantData(ctx context.Context, tx *store.Store, plant *models.Plant, t time.Time) error {
-	plant.Refresh(t)
-	return tx.Plant.Update(ctx, plant)
+	var neighbours []*models.Plant
+	if plant.Alive() && plant.DueForRefresh(t) {
+		var err error
+		neighbours, err = getPlantNeighbours(ctx, tx, plant)
+		if err != nil {
+			return err
+		}
+	}
+
+	lastCalculatedAt := plant.LastCalculatedAt()
+
+	plant.Refresh(t, neighbours...)
+	if err := tx.Plant.Update(ctx, plant); err != nil {
+		return err
+	}
+
+	if err := insertPlantEvents(ctx, tx, plant.PopEvents()); err != nil {
+		return err
+	}
+
+	if playerXp := models.PlayerXpForSurvivalMilestones(plant, lastCalculatedAt, plant.LastCalculatedAt()); playerXp > 0 {
+		if _, err := awardPlayerXp(ctx, tx, plant.OwnerID, playerXp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkCommunityWatering checks whether a player may act on a plant they do not own.
+// Other players can only water a plant whose owner allows it, and only up to models.CommunityWateringDailyCap times a day.
+func checkCommunityWatering(ctx context.Context, tx *store.Store, plant *models.Plant, userID string, action models.PlantAction, t time.Time) error {
+	if action != models.PlantActionWater {
+		return ErrUnauthorisedPlantAction
+	}
+
+	listedHelper := false
+	if plant.CarePermission == models.CarePermissionHelpers {
+		var err error
+		listedHelper, err = tx.Plant.IsHelper(ctx, plant.ID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !plant.AllowsCommunityWateringBy(userID, listedHelper) {
+		return ErrUnauthorisedPlantAction
+	}
+
+	count, err := tx.PlantEvent.CountCommunityWateringsByActorID(ctx, userID, t.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	if count >= models.CommunityWateringDailyCap {
+		return ErrCommunityWateringCapReached
+	}
+
+	return nil
+}
+
+func insertPlantEvents(ctx context.Context, tx *store.Store, events []*models.PlantEvent) error {
+	for _, event := range events {
+		if err := tx.PlantEvent.Insert(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPlantNeighbours returns the living plants on the same soil whose malice reaches the plant.
+func getPlantNeighbours(ctx context.Context, tx *store.Store, plant *models.Plant) ([]*models.Plant, error) {
+	if plant.Soil == nil || plant.Soil.ID == "" {
+		return nil, nil
+	}
+
+	nearbyPlants, err := tx.Plant.GetBySoilIDAndProximity(ctx, plant.Soil.ID, plant.Centre(), models.MaliceReachM)
+	if err != nil {
+		return nil, err
+	}
+
+	neighbours := make([]*models.Plant, 0, len(nearbyPlants))
+	for _, nearbyPlant := range nearbyPlants {
+		if nearbyPlant.ID != plant.ID {
+			neighbours = append(neighbours, nearbyPlant)
+		}
+	}
+
+	return neighbours, nil
 }
 
 func (s *plantService) isPlantValidForSoil(plantCircleMeta models.CircleMeta, nearbyPlants []*models.Plant) bool {
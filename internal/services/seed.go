@@ -17,7 +17,7 @@ type SeedService interface {
 	GetUserSeeds(context.Context, string) ([]*models.SeedGroup, error)
 	GetSeed(context.Context, string, string) (*models.Seed, error)
 	GiveUserNewSeeds(context.Context, string, int) ([]*models.SeedGroup, error)
-	PlantSeed(context.Context, string, dto.PlantSeedReq) (*models.Plant, error)
+	PlantSeed(context.Context, string, dto.PlantSeedReq) (*models.Plant, *models.PlayerProgress, error)
 	CheckWhenUserCanRequestSeed(ctx context.Context, userID string) (*time.Time, error)
 	WithStore(*store.Store) SeedService
 }
@@ -45,13 +45,15 @@ type seedService struct {
 	soilService  SoilService
 	plantService PlantService
 	store        *store.Store
+	clock        models.Clock
 }
 
-func NewSeedService(store *store.Store, soilService SoilService, plantService PlantService) SeedService {
+func NewSeedService(store *store.Store, soilService SoilService, plantService PlantService, clock models.Clock) SeedService {
 	return &seedService{
 		store:        store,
 		soilService:  soilService,
 		plantService: plantService,
+		clock:        clock,
 	}
 }
 
@@ -107,15 +109,15 @@ func (s *seedService) GetSeed(ctx context.Context, userID, seedID string) (*mode
 	return seed, nil
 }
 
-func (s *seedService) PlantSeed(ctx context.Context, seedID string, dto dto.PlantSeedReq) (*models.Plant, error) {
+func (s *seedService) PlantSeed(ctx context.Context, seedID string, dto dto.PlantSeedReq) (*models.Plant, *models.PlayerProgress, error) {
 	userID, err := contextkeys.GetUserIDFromCtx(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	transaction, err := s.store.Begin()
 	if err != nil {
-		return nil, store.ErrTransactionCouldNotStart
+		return nil, nil, store.ErrTransactionCouldNotStart
 	}
 	//nolint:errcheck
 	defer transaction.Rollback()
@@ -126,11 +128,11 @@ func (s *seedService) PlantSeed(ctx context.Context, seedID string, dto dto.Plan
 
 	seed, err := tx.Seed.Get(ctx, seedID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if seed.OwnerID != userID {
-		return nil, ErrUnauthorizedSeedPlanting
+		return nil, nil, ErrUnauthorizedSeedPlanting
 	}
 
 	targetCentre := models.Coordinates{Lat: *dto.Latitude, Lon: *dto.Longitude}
@@ -138,13 +140,13 @@ func (s *seedService) PlantSeed(ctx context.Context, seedID string, dto dto.Plan
 
 	nearbySoils, err := tx.Soil.GetAllInProximity(ctx, targetCentre, models.SoilRadiusMLarge)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if len(nearbySoils) == 0 {
 		soil, err := soilServiceWithTx.CreateSoil(ctx, targetCentre, nearbySoils)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		nearbySoils = append(nearbySoils, soil)
 	}
@@ -157,23 +159,33 @@ func (s *seedService) PlantSeed(ctx context.Context, seedID string, dto dto.Plan
 	}
 
 	if targetSoil == nil {
-		return nil, ErrNotPossibleToPlantSeed
+		return nil, nil, ErrNotPossibleToPlantSeed
 	}
 
 	plant, err := plantServiceWithTx.CreatePlant(ctx, targetSoil, seed, targetCentre)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := tx.Seed.MarkAsPlanted(ctx, seed.ID); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	progress, err := awardPlayerXp(ctx, tx, userID, models.PlayerXpForPlanting())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	progress.NewAchievements, err = evaluateAchievements(ctx, tx, userID, s.clock.Now())
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if err := transaction.Commit(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return plant, nil
+	return plant, progress, nil
 }
 
 func (s *seedService) CheckWhenUserCanRequestSeed(ctx context.Context, userID string) (*time.Time, error) {
@@ -187,7 +199,7 @@ func (s *seedService) CheckWhenUserCanRequestSeed(ctx context.Context, userID st
 	}
 
 	timeAvailable := lastFulfilledSeedRequest.Add(SeedRequestCooldownDuration)
-	if time.Since(lastFulfilledSeedRequest) < SeedRequestCooldownDuration {
+	if s.clock.Now().Sub(lastFulfilledSeedRequest) < SeedRequestCooldownDuration {
 		return &timeAvailable, nil
 	}
 
@@ -210,8 +222,8 @@ func (s *seedService) GiveUserNewSeeds(ctx context.Context, userID string, count
 	}
 
 	if !lastFulfilledSeedRequest.IsZero() {
-		if time.Since(lastFulfilledSeedRequest) < SeedRequestCooldownDuration {
-			if err := recordFailedSeedRequest(ctx, transaction, tx, userID, count); err != nil {
+		if s.clock.Now().Sub(lastFulfilledSeedRequest) < SeedRequestCooldownDuration {
+			if err := recordFailedSeedRequest(ctx, transaction, tx, userID, count, s.clock.Now()); err != nil {
 				return nil, err
 			}
 			timeAvailable := lastFulfilledSeedRequest.Add(SeedRequestCooldownDuration)
@@ -226,7 +238,7 @@ func (s *seedService) GiveUserNewSeeds(ctx context.Context, userID string, count
 		}
 	}
 
-	if err := tx.Seed.InsertSeedRequest(ctx, userID, time.Now(), true, count); err != nil {
+	if err := tx.Seed.InsertSeedRequest(ctx, userID, s.clock.Now(), true, count); err != nil {
 		return nil, err
 	}
 
@@ -237,8 +249,8 @@ func (s *seedService) GiveUserNewSeeds(ctx context.Context, userID string, count
 	return s.GetUserSeeds(ctx, userID)
 }
 
-func recordFailedSeedRequest(ctx context.Context, transaction *store.Transaction, txStore *store.Store, userID string, count int) error {
-	if err := txStore.Seed.InsertSeedRequest(ctx, userID, time.Now(), false, count); err != nil {
+func recordFailedSeedRequest(ctx context.Context, transaction *store.Transaction, txStore *store.Store, userID string, count int, t time.Time) error {
+	if err := txStore.Seed.InsertSeedRequest(ctx, userID, t, false, count); err != nil {
 		return err
 	}
 
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/contextkeys"
+	"github.com/jasonuc/moota/internal/moderation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedRequestCooldown(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping seed service integration tests")
+	}
+
+	store := newTestStore(t, "seed_service_init.sql")
+	clock := &fakeClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	plantService := NewPlantService(store, clock, moderation.NewDefaultFilter())
+	seedService := NewSeedService(store, NewSoilSerivce(store), plantService, clock)
+
+	userID := "00000000-0000-4000-a000-000000000001"
+	ctx := contextkeys.SetUserIDCtx(context.Background(), userID)
+
+	t.Run("first request is fulfilled", func(t *testing.T) {
+		_, err := seedService.GiveUserNewSeeds(ctx, userID, 3)
+		assert.NoError(t, err)
+	})
+
+	t.Run("request during cooldown is refused", func(t *testing.T) {
+		clock.Advance(SeedRequestCooldownDuration - time.Hour)
+
+		_, err := seedService.GiveUserNewSeeds(ctx, userID, 3)
+
+		var cooldownErr *ErrSeedRequestInCooldown
+		if assert.ErrorAs(t, err, &cooldownErr) {
+			assert.WithinDuration(t, clock.Now().Add(time.Hour), cooldownErr.TimeAvailable, time.Second)
+		}
+	})
+
+	t.Run("request after cooldown is fulfilled", func(t *testing.T) {
+		clock.Advance(time.Hour)
+
+		timeAvailable, err := seedService.CheckWhenUserCanRequestSeed(ctx, userID)
+		assert.NoError(t, err)
+		assert.Nil(t, timeAvailable)
+
+		_, err = seedService.GiveUserNewSeeds(ctx, userID, 3)
+		assert.NoError(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 
 	"github.com/jasonuc/moota/internal/models"
@@ -11,6 +12,7 @@ import (
 
 type SoilService interface {
 	CreateSoil(context.Context, models.Coordinates, []*models.Soil) (*models.Soil, error)
+	GetSoilMap(context.Context, models.Coordinates, float64) ([]*models.SoilWithOccupancy, error)
 	WithStore(*store.Store) SoilService
 }
 
@@ -31,9 +33,52 @@ func (s *soilService) WithStore(store *store.Store) SoilService {
 }
 
 var (
-	ErrNoSoilGenerated = errors.New("no soil generated")
+	ErrNoSoilGenerated      = errors.New("no soil generated")
+	ErrInvalidSoilMapRadius = fmt.Errorf("radius must be between 1 and %d metres", SoilMapMaxRadiusM)
 )
 
+const (
+	SoilMapDefaultRadiusM = 250
+	SoilMapMaxRadiusM     = 1000
+)
+
+// GetSoilMap returns the soils that are at least partly within radiusM of coords, with where there is still room to plant in them.
+func (s *soilService) GetSoilMap(ctx context.Context, coords models.Coordinates, radiusM float64) ([]*models.SoilWithOccupancy, error) {
+	// written this way round so NaN is rejected too
+	if !(coords.Lat >= -90 && coords.Lat <= 90 && coords.Lon >= -180 && coords.Lon <= 180) {
+		return nil, ErrInvalidCoordinates
+	}
+
+	if radiusM < 1 || radiusM > SoilMapMaxRadiusM {
+		return nil, ErrInvalidSoilMapRadius
+	}
+
+	soils, err := s.store.Soil.GetAllInProximity(ctx, coords, radiusM+models.SoilRadiusMLarge)
+	if err != nil {
+		return nil, err
+	}
+
+	// every plant is inside its soil so this reaches all the plants of the soils found
+	plantCircles, err := s.store.Plant.GetCirclesBySoilInProximity(ctx, coords, radiusM+2*models.SoilRadiusMLarge)
+	if err != nil {
+		return nil, err
+	}
+
+	soilMap := make([]*models.SoilWithOccupancy, 0, len(soils))
+	for _, soil := range soils {
+		if soil.Centre().DistanceM(coords) > radiusM+soil.RadiusM() {
+			continue
+		}
+
+		soilMap = append(soilMap, &models.SoilWithOccupancy{
+			Soil:      *soil,
+			Occupancy: soil.Occupancy(plantCircles[soil.ID]),
+		})
+	}
+
+	return soilMap, nil
+}
+
 func (s *soilService) CreateSoil(ctx context.Context, centre models.Coordinates, nearbySoils []*models.Soil) (*models.Soil, error) {
 	radius := models.RandomSoilRadius(models.RandomSoilRadiusParam{MaxRadius: math.Inf(1)})
 	newSoilCircleMeta := models.NewCircleMeta(centre, radius)
@@ -0,0 +1,13 @@
+INSERT INTO users (id, username, email, password_hash, role) VALUES
+  ('00000000-0000-4000-a000-000000000001', 'admin', 'admin@example.com', '\x0123456789ABCDEF', 'admin'),
+  ('00000000-0000-4000-a000-000000000002', 'rudeword', 'rude@example.com', '\x0123456789ABCDEF', 'user');
+
+INSERT INTO soils (id, soil_type, water_retention, nutrient_richness, radius_m, centre) VALUES
+  ('00000000-0000-4000-c000-000000000101', 'loam', 0.55, 0.75, 22.0,
+   ST_GeogFromText('POINT(-73.965355 40.782865)'));
+
+INSERT INTO plants (id, nickname, hp, dead, time_of_death, owner_id, centre, fuzzed_centre, radius_m, soil_id, optimal_soil, botanical_name, woe, frolic, dread, malice) VALUES
+  ('00000000-0000-4000-d000-000000000001', 'Fuzzy Sprout', 0.0, true, NOW(), '00000000-0000-4000-a000-000000000002',
+   ST_GeogFromText('POINT(-73.965355 40.782865)'), ST_GeogFromText('POINT(-73.965355 40.783265)'), 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3),
+  ('00000000-0000-4000-d000-000000000002', 'Admin Sprout', 0.0, true, NOW(), '00000000-0000-4000-a000-000000000001',
+   ST_GeogFromText('POINT(-73.965555 40.782865)'), ST_GeogFromText('POINT(-73.965555 40.783265)'), 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3);
@@ -0,0 +1,2 @@
+-- no fixtures needed, users are registered through the service
+SELECT 1;
@@ -10,15 +10,18 @@ import (
 type UserService interface {
 	GetUser(context.Context, string) (*models.User, error)
 	GetUserProfile(context.Context, string) (*models.UserProfile, error)
+	ExportUserData(context.Context, string) (*models.UserDataExport, error)
 }
 
 type userService struct {
 	store *store.Store
+	clock models.Clock
 }
 
-func NewUserService(store *store.Store) UserService {
+func NewUserService(store *store.Store, clock models.Clock) UserService {
 	return &userService{
 		store: store,
+		clock: clock,
 	}
 }
 
@@ -61,11 +64,87 @@ func (s *userService) GetUserProfile(ctx context.Context, username string) (*mod
 		return nil, err
 	}
 
+	achievements, err := tx.Achievement.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := transaction.Commit(); err != nil {
 		return nil, err
 	}
 
-	userProfile := models.NewUserProfile(user, plantCount, seedCount, plants)
+	userProfile := models.NewUserProfile(user, plantCount, seedCount, plants, achievements)
 
 	return userProfile, nil
 }
+
+// ExportUserData gathers everything stored about the user in one transaction so the export is consistent.
+func (s *userService) ExportUserData(ctx context.Context, userID string) (*models.UserDataExport, error) {
+	transaction, err := s.store.Begin()
+	if err != nil {
+		return nil, store.ErrTransactionCouldNotStart
+	}
+	//nolint:errcheck
+	defer transaction.Rollback()
+
+	tx := s.store.WithTx(transaction)
+
+	now := s.clock.Now()
+	export := &models.UserDataExport{ExportedAt: now}
+
+	export.User, err = tx.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Seeds, err = tx.Seed.GetAllByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Plants, err = tx.Plant.GetByOwnerID(ctx, userID, &store.GetPlantsOpts{IncludeDeceased: true})
+	if err != nil {
+		return nil, err
+	}
+
+	export.SeedRequests, err = tx.Seed.GetSeedRequestsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Sessions, err = tx.RefreshToken.GetSessionsByUserID(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Identities, err = tx.Identity.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// awardPlayerXp gives the user xp and saves their new level using the store it is given,
+// so it is committed or rolled back with the rest of the caller's transaction.
+func awardPlayerXp(ctx context.Context, tx *store.Store, userID string, xp int64) (*models.PlayerProgress, error) {
+	user, err := tx.User.GetByIDForUpdate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := user.GainXp(xp)
+	if xp == 0 {
+		return progress, nil
+	}
+
+	if err := tx.User.UpdateProgress(ctx, user.ID, user.LevelMeta); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportUserData(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping user service integration tests")
+	}
+
+	store := newTestStore(t, "seed_service_init.sql")
+	clock := &fakeClock{now: time.Now()}
+	userService := NewUserService(store, clock)
+
+	ctx := context.Background()
+	userID := "00000000-0000-4000-a000-000000000001"
+
+	if err := store.Seed.InsertSeedRequest(ctx, userID, clock.Now(), true, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := userService.ExportUserData(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", export.User.Username)
+	assert.Len(t, export.SeedRequests, 1)
+	assert.NotNil(t, export.Seeds)
+	assert.NotNil(t, export.Plants)
+	assert.NotNil(t, export.Sessions)
+
+	_, err = userService.ExportUserData(ctx, "00000000-0000-4000-a000-000000000002")
+	assert.Error(t, err)
+}
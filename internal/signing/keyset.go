@@ -0,0 +1,205 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey        = errors.New("token signed with an unknown key")
+	ErrUnsupportedKey    = errors.New("unsupported key, expected an RSA or Ed25519 key in PEM format")
+	ErrMethodKeyMismatch = errors.New("token signing method does not match its key")
+)
+
+// Key is a key tokens are signed or verified with. Private is nil for keys that are only used for verification.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private any
+	Public  any
+}
+
+// KeySet signs tokens with one key and verifies them with any of its keys, picked by the kid header.
+// Keeping the previous signing keys around for verification lets keys be rotated without signing everyone out.
+type KeySet struct {
+	signing      *Key
+	verification map[string]*Key
+}
+
+// NewHMACKeySet signs and verifies tokens with HS256 and a shared secret. The secret is never published in the JWKS.
+func NewHMACKeySet(secret []byte) *KeySet {
+	key := &Key{Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+	return &KeySet{
+		signing:      key,
+		verification: map[string]*Key{"": key},
+	}
+}
+
+// LoadKeySet signs tokens with the private key in signingKeyFile and also accepts tokens signed by the keys
+// in verificationKeyFiles, which may hold public or private keys.
+func LoadKeySet(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	signing, err := loadKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if signing.Private == nil {
+		return nil, fmt.Errorf("%s: signing key must be a private key", signingKeyFile)
+	}
+
+	keySet := &KeySet{
+		signing:      signing,
+		verification: map[string]*Key{signing.ID: signing},
+	}
+
+	for _, file := range verificationKeyFiles {
+		key, err := loadKey(file)
+		if err != nil {
+			return nil, err
+		}
+		keySet.verification[key.ID] = key
+	}
+
+	return keySet, nil
+}
+
+// Sign signs the claims with the signing key, setting the kid header to its ID.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	if ks.signing.ID != "" {
+		token.Header["kid"] = ks.signing.ID
+	}
+	return token.SignedString(ks.signing.Private)
+}
+
+// Keyfunc finds the key a token was signed with for jwt.Parse. The token's alg must be the one its key is used with
+// so a public key can never be used as an HMAC secret.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := ks.verification[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrMethodKeyMismatch
+	}
+
+	return key.Public, nil
+}
+
+// ValidMethods are the algs of the keys in the set, for jwt.WithValidMethods.
+func (ks *KeySet) ValidMethods() []string {
+	seen := make(map[string]bool)
+	methods := make([]string, 0, len(ks.verification))
+	for _, key := range ks.verification {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS returns the public keys in the set. HMAC secrets are left out.
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(ks.verification))}
+	for _, key := range ks.verification {
+		if jwk, ok := publicJWK(key); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+func loadKey(file string) (*Key, error) {
+	pemBytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+
+	key := new(Key)
+	if private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, private, &private.PublicKey
+	} else if private, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, private, private.(ed25519.PrivateKey).Public()
+	} else if public, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		key.Method, key.Public = jwt.SigningMethodRS256, public
+	} else if public, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		key.Method, key.Public = jwt.SigningMethodEdDSA, public
+	} else {
+		return nil, fmt.Errorf("%s: %w", file, ErrUnsupportedKey)
+	}
+
+	jwk, _ := publicJWK(key)
+	key.ID, err = thumbprint(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// thumbprint is the RFC 7638 thumbprint of the key, which is used as its kid.
+func thumbprint(jwk JWK) (string, error) {
+	var members any
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	default:
+		return "", ErrUnsupportedKey
+	}
+
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func publicJWK(key *Key) (JWK, bool) {
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
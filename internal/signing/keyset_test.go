@@ -0,0 +1,135 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaDER, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaFile := writePEM(t, "rsa.pem", "PRIVATE KEY", rsaDER)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edFile := writePEM(t, "ed25519.pem", "PRIVATE KEY", edDER)
+
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublicFile := writePEM(t, "ed25519.pub.pem", "PUBLIC KEY", edPublicDER)
+
+	claims := jwt.RegisteredClaims{Subject: "user", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	parse := func(ks *KeySet, token string) error {
+		_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, ks.Keyfunc, jwt.WithValidMethods(ks.ValidMethods()))
+		return err
+	}
+
+	t.Run("signs with a kid and verifies", func(t *testing.T) {
+		for _, file := range []string{rsaFile, edFile} {
+			ks, err := LoadKeySet(file, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := ks.Sign(claims)
+			assert.NoError(t, err)
+			assert.NoError(t, parse(ks, token))
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			assert.NoError(t, err)
+			assert.Equal(t, ks.signing.ID, parsed.Header["kid"])
+		}
+	})
+
+	t.Run("previous keys still verify after rotation", func(t *testing.T) {
+		previous, err := LoadKeySet(edFile, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		oldToken, err := previous.Sign(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rotated, err := LoadKeySet(rsaFile, []string{edPublicFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, parse(rotated, oldToken))
+
+		withoutPrevious, err := LoadKeySet(rsaFile, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Error(t, parse(withoutPrevious, oldToken))
+	})
+
+	t.Run("jwks has every public key", func(t *testing.T) {
+		ks, err := LoadKeySet(rsaFile, []string{edPublicFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jwks := ks.JWKS()
+		if assert.Len(t, jwks.Keys, 2) {
+			algs := []string{jwks.Keys[0].Alg, jwks.Keys[1].Alg}
+			assert.ElementsMatch(t, []string{"RS256", "EdDSA"}, algs)
+		}
+
+		assert.Empty(t, NewHMACKeySet([]byte("secret")).JWKS().Keys, "expected HMAC secrets never to be published")
+	})
+
+	t.Run("public keys can't be used as HMAC secrets", func(t *testing.T) {
+		ks, err := LoadKeySet(edFile, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		forged.Header["kid"] = ks.signing.ID
+		token, err := forged.SignedString([]byte(edKey.Public().(ed25519.PublicKey)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Error(t, parse(ks, token))
+	})
+
+	t.Run("signing key must be private", func(t *testing.T) {
+		_, err := LoadKeySet(edPublicFile, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type AchievementStore interface {
+	GetByUserID(context.Context, string) ([]*models.Achievement, error)
+	GetStatsByUserID(context.Context, string, time.Time) (*models.AchievementStats, error)
+	Insert(context.Context, *models.Achievement) (bool, error)
+	IncrementWaterings(context.Context, string) error
+	IncrementCommunityWaterings(context.Context, string) error
+}
+
+type achievementStore struct {
+	db Querier
+}
+
+func (s *achievementStore) GetByUserID(ctx context.Context, userID string) ([]*models.Achievement, error) {
+	q := `SELECT user_id, achievement, earned_at FROM achievements
+			WHERE user_id = $1
+			ORDER BY earned_at ASC;`
+
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	achievements := make([]*models.Achievement, 0)
+	for rows.Next() {
+		var achievementID models.AchievementID
+		achievement := new(models.Achievement)
+
+		if err := rows.Scan(&achievement.UserID, &achievementID, &achievement.EarnedAt); err != nil {
+			return nil, err
+		}
+
+		meta, err := models.GetAchievementMeta(achievementID)
+		if err != nil {
+			// achievement has been removed from the catalog
+			continue
+		}
+		achievement.AchievementMeta = meta
+
+		achievements = append(achievements, achievement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return achievements, nil
+}
+
+// GetStatsByUserID aggregates the user's history for achievement evaluation.
+// now is used as the end of the lives of plants that are still alive.
+func (s *achievementStore) GetStatsByUserID(ctx context.Context, userID string, now time.Time) (*models.AchievementStats, error) {
+	q := `SELECT
+			(SELECT count(*) FROM plants WHERE owner_id = $1),
+			(SELECT count(DISTINCT s.soil_type) FROM plants p JOIN soils s ON p.soil_id = s.id WHERE p.owner_id = $1),
+			(SELECT COALESCE(MAX(level), 0) FROM plants WHERE owner_id = $1),
+			(SELECT COALESCE(EXTRACT(EPOCH FROM MAX(COALESCE(time_of_death, $2) - time_planted)), 0)::BIGINT FROM plants WHERE owner_id = $1),
+			COALESCE((SELECT waterings FROM user_stats WHERE user_id = $1), 0),
+			COALESCE((SELECT community_waterings FROM user_stats WHERE user_id = $1), 0);`
+
+	stats := new(models.AchievementStats)
+	var longestPlantLifeSeconds int64
+
+	err := s.db.QueryRowContext(ctx, q, userID, now).Scan(
+		&stats.PlantsPlanted, &stats.DistinctSoilTypes, &stats.HighestPlantLevel, &longestPlantLifeSeconds, &stats.Waterings,
+		&stats.CommunityWaterings,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.LongestPlantLife = time.Duration(longestPlantLifeSeconds) * time.Second
+
+	return stats, nil
+}
+
+// Insert records an earned achievement. It reports false if the user had already earned it.
+func (s *achievementStore) Insert(ctx context.Context, achievement *models.Achievement) (bool, error) {
+	q := `INSERT INTO achievements (user_id, achievement, earned_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, achievement) DO NOTHING;`
+
+	res, err := s.db.ExecContext(ctx, q, achievement.UserID, achievement.ID, achievement.EarnedAt)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (s *achievementStore) IncrementWaterings(ctx context.Context, userID string) error {
+	q := `INSERT INTO user_stats (user_id, waterings)
+			VALUES ($1, 1)
+			ON CONFLICT (user_id) DO UPDATE SET waterings = user_stats.waterings + 1;`
+
+	_, err := s.db.ExecContext(ctx, q, userID)
+	return err
+}
+
+func (s *achievementStore) IncrementCommunityWaterings(ctx context.Context, userID string) error {
+	q := `INSERT INTO user_stats (user_id, community_waterings)
+			VALUES ($1, 1)
+			ON CONFLICT (user_id) DO UPDATE SET community_waterings = user_stats.community_waterings + 1;`
+
+	_, err := s.db.ExecContext(ctx, q, userID)
+	return err
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type AdminStore interface {
+	InsertAuditLogEntry(context.Context, *models.AuditLogEntry) error
+	GetAuditLog(context.Context, int, int) ([]*models.AuditLogEntry, error)
+	GetServerStats(context.Context, time.Time) (*models.ServerStats, error)
+}
+
+type adminStore struct {
+	db Querier
+}
+
+func (s *adminStore) InsertAuditLogEntry(ctx context.Context, entry *models.AuditLogEntry) error {
+	q := `INSERT INTO admin_audit_log (actor_id, action, target_type, target_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;`
+
+	details := entry.Details
+	if len(details) == 0 {
+		details = []byte("{}")
+	}
+
+	return s.db.QueryRowContext(
+		ctx, q, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, []byte(details), entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+// GetAuditLog returns the audit log newest first.
+func (s *adminStore) GetAuditLog(ctx context.Context, limit, offset int) ([]*models.AuditLogEntry, error) {
+	q := `SELECT id, actor_id, action, target_type, target_id, details, created_at
+		FROM admin_audit_log
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2;`
+
+	rows, err := s.db.QueryContext(ctx, q, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	entries := make([]*models.AuditLogEntry, 0)
+	for rows.Next() {
+		entry := new(models.AuditLogEntry)
+		var details []byte
+		if err := rows.Scan(
+			&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID, &details, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entry.Details = details
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *adminStore) GetServerStats(ctx context.Context, now time.Time) (*models.ServerStats, error) {
+	q := `SELECT
+			(SELECT count(*) FROM users),
+			(SELECT count(*) FROM users WHERE created_at > $1 - INTERVAL '1 day'),
+			(SELECT count(DISTINCT family_id) FROM refresh_tokens WHERE revoked_at IS NULL AND expires_at > $1),
+			(SELECT count(*) FROM plants WHERE dead = false),
+			(SELECT count(*) FROM plants WHERE dead = true),
+			(SELECT count(*) FROM seeds WHERE planted = false);`
+
+	stats := new(models.ServerStats)
+	err := s.db.QueryRowContext(ctx, q, now).Scan(
+		&stats.Users, &stats.NewUsers, &stats.ActiveSessions, &stats.AlivePlants, &stats.DeceasedPlants, &stats.UnplantedSeeds,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
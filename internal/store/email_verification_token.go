@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type EmailVerificationTokenStore interface {
+	Insert(context.Context, *models.EmailVerificationToken) error
+	GetByHash(context.Context, []byte) (*models.EmailVerificationToken, error)
+	MarkUsed(context.Context, string, time.Time) error
+	InvalidateByUserID(context.Context, string, time.Time) error
+}
+
+type emailVerificationTokenStore struct {
+	db Querier
+}
+
+func (s *emailVerificationTokenStore) Insert(ctx context.Context, token *models.EmailVerificationToken) error {
+	q := `INSERT INTO email_verification_tokens (user_id, email, hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`
+
+	return s.db.QueryRowContext(
+		ctx, q, token.UserID, token.Email, token.Hash, token.CreatedAt, token.ExpiresAt,
+	).Scan(&token.ID)
+}
+
+// GetByHash locks the token's row so it cannot be used twice concurrently.
+func (s *emailVerificationTokenStore) GetByHash(ctx context.Context, hash []byte) (*models.EmailVerificationToken, error) {
+	q := `SELECT id, user_id, email, hash, created_at, expires_at, used_at
+		FROM email_verification_tokens WHERE hash = $1
+		FOR UPDATE;`
+
+	token := new(models.EmailVerificationToken)
+	err := s.db.QueryRowContext(ctx, q, hash).Scan(
+		&token.ID, &token.UserID, &token.Email, &token.Hash, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrEmailVerificationTokenNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (s *emailVerificationTokenStore) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	q := `UPDATE email_verification_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL;`
+
+	res, err := s.db.ExecContext(ctx, q, id, usedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrEmailVerificationTokenNotFound
+	}
+
+	return nil
+}
+
+// InvalidateByUserID uses up every outstanding token of the user so only the newest one sent works.
+func (s *emailVerificationTokenStore) InvalidateByUserID(ctx context.Context, userID string, at time.Time) error {
+	q := `UPDATE email_verification_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL;`
+
+	_, err := s.db.ExecContext(ctx, q, userID, at)
+	return err
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type IdentityStore interface {
+	Insert(context.Context, *models.Identity) error
+	GetByProviderSubject(context.Context, string, string) (*models.Identity, error)
+	GetByUserID(context.Context, string) ([]*models.Identity, error)
+	UpdateLastLogin(context.Context, string, time.Time) error
+	Delete(context.Context, string, string) error
+	InsertLoginState(context.Context, *models.OIDCLoginState) error
+	TakeLoginState(context.Context, string) (*models.OIDCLoginState, error)
+	InsertSignup(context.Context, *models.OIDCSignup) error
+	TakeSignup(context.Context, []byte) (*models.OIDCSignup, error)
+}
+
+type identityStore struct {
+	db Querier
+}
+
+func (s *identityStore) Insert(ctx context.Context, identity *models.Identity) error {
+	q := `INSERT INTO identities (user_id, provider, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;`
+
+	return s.db.QueryRowContext(
+		ctx, q, identity.UserID, identity.Provider, identity.Subject, nullIfEmpty(identity.Email), identity.CreatedAt, identity.LastLoginAt,
+	).Scan(&identity.ID)
+}
+
+func (s *identityStore) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	q := `SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM identities WHERE provider = $1 AND subject = $2;`
+
+	identity, err := scanIdentity(s.db.QueryRowContext(ctx, q, provider, subject))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (s *identityStore) GetByUserID(ctx context.Context, userID string) ([]*models.Identity, error) {
+	q := `SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM identities WHERE user_id = $1
+		ORDER BY created_at ASC;`
+
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	identities := make([]*models.Identity, 0)
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (s *identityStore) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	q := `UPDATE identities SET last_login_at = $2 WHERE id = $1;`
+
+	_, err := s.db.ExecContext(ctx, q, id, at)
+	return err
+}
+
+// Delete removes the user's identity with the given ID.
+func (s *identityStore) Delete(ctx context.Context, userID, id string) error {
+	q := `DELETE FROM identities WHERE user_id = $1 AND id = $2;`
+
+	res, err := s.db.ExecContext(ctx, q, userID, id)
+	if err != nil {
+		if strings.Contains(err.Error(), ErrInvalidUUIDSyntax) {
+			return models.ErrIdentityNotFound
+		}
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+func (s *identityStore) InsertLoginState(ctx context.Context, state *models.OIDCLoginState) error {
+	q := `INSERT INTO oidc_login_states (state, provider, nonce, code_verifier, link_user_id, set_password_user_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	_, err := s.db.ExecContext(
+		ctx, q, state.State, state.Provider, state.Nonce, state.CodeVerifier, state.LinkUserID, state.SetPasswordUserID,
+		state.CreatedAt, state.ExpiresAt,
+	)
+	return err
+}
+
+// TakeLoginState deletes the login state and returns it, so every state can only be used once.
+func (s *identityStore) TakeLoginState(ctx context.Context, state string) (*models.OIDCLoginState, error) {
+	q := `DELETE FROM oidc_login_states WHERE state = $1
+		RETURNING state, provider, nonce, code_verifier, link_user_id, set_password_user_id, created_at, expires_at;`
+
+	loginState := new(models.OIDCLoginState)
+	err := s.db.QueryRowContext(ctx, q, state).Scan(
+		&loginState.State, &loginState.Provider, &loginState.Nonce, &loginState.CodeVerifier,
+		&loginState.LinkUserID, &loginState.SetPasswordUserID, &loginState.CreatedAt, &loginState.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrOIDCLoginStateNotFound
+		}
+		return nil, err
+	}
+
+	return loginState, nil
+}
+
+func (s *identityStore) InsertSignup(ctx context.Context, signup *models.OIDCSignup) error {
+	q := `INSERT INTO oidc_signups (hash, provider, subject, email, email_verified, suggested_username, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id;`
+
+	return s.db.QueryRowContext(
+		ctx, q, signup.Hash, signup.Provider, signup.Subject, nullIfEmpty(signup.Email), signup.EmailVerified,
+		signup.SuggestedUsername, signup.CreatedAt, signup.ExpiresAt,
+	).Scan(&signup.ID)
+}
+
+// TakeSignup deletes the signup and returns it, so every signup can only be completed once.
+func (s *identityStore) TakeSignup(ctx context.Context, hash []byte) (*models.OIDCSignup, error) {
+	q := `DELETE FROM oidc_signups WHERE hash = $1
+		RETURNING id, hash, provider, subject, email, email_verified, suggested_username, created_at, expires_at;`
+
+	signup := new(models.OIDCSignup)
+	var email sql.NullString
+	err := s.db.QueryRowContext(ctx, q, hash).Scan(
+		&signup.ID, &signup.Hash, &signup.Provider, &signup.Subject, &email, &signup.EmailVerified,
+		&signup.SuggestedUsername, &signup.CreatedAt, &signup.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrOIDCSignupNotFound
+		}
+		return nil, err
+	}
+
+	signup.Email = email.String
+	return signup, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIdentity(row rowScanner) (*models.Identity, error) {
+	identity := new(models.Identity)
+	var email sql.NullString
+	if err := row.Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &email, &identity.CreatedAt, &identity.LastLoginAt,
+	); err != nil {
+		return nil, err
+	}
+	identity.Email = email.String
+	return identity, nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type LoginFailuresStore interface {
+	Get(context.Context, string) (*models.LoginFailures, error)
+	Record(context.Context, string, time.Time, time.Time) (*models.LoginFailures, error)
+	Clear(context.Context, string) error
+}
+
+type loginFailuresStore struct {
+	db Querier
+}
+
+// Get returns nil if there are no failures recorded for the key.
+func (s *loginFailuresStore) Get(ctx context.Context, key string) (*models.LoginFailures, error) {
+	q := `SELECT key, failures, last_failed_at FROM login_failures WHERE key = $1;`
+
+	failures := new(models.LoginFailures)
+	err := s.db.QueryRowContext(ctx, q, key).Scan(&failures.Key, &failures.Failures, &failures.LastFailedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+// Record counts a failed login at failedAt. Failures from before resetBefore are forgotten and counting starts again.
+func (s *loginFailuresStore) Record(ctx context.Context, key string, failedAt, resetBefore time.Time) (*models.LoginFailures, error) {
+	q := `INSERT INTO login_failures (key, failures, last_failed_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_failures.last_failed_at < $3 THEN 1 ELSE login_failures.failures + 1 END,
+			last_failed_at = $2
+		RETURNING key, failures, last_failed_at;`
+
+	failures := new(models.LoginFailures)
+	err := s.db.QueryRowContext(ctx, q, key, failedAt, resetBefore).Scan(&failures.Key, &failures.Failures, &failures.LastFailedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+func (s *loginFailuresStore) Clear(ctx context.Context, key string) error {
+	q := `DELETE FROM login_failures WHERE key = $1;`
+
+	_, err := s.db.ExecContext(ctx, q, key)
+	return err
+}
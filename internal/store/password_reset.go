@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type PasswordResetStore interface {
+	InsertToken(context.Context, *models.PasswordResetToken) error
+	GetTokenByHash(context.Context, []byte) (*models.PasswordResetToken, error)
+	MarkTokenUsed(context.Context, string, time.Time) error
+	InvalidateTokensByUserID(context.Context, string, time.Time) error
+	InsertRequest(context.Context, *models.PasswordResetRequest) error
+	GetRequestsByIPAddressSince(context.Context, string, time.Time) (int, *time.Time, error)
+	CountRequestsByUserIDSince(context.Context, string, time.Time) (int, error)
+}
+
+type passwordResetStore struct {
+	db Querier
+}
+
+func (s *passwordResetStore) InsertToken(ctx context.Context, token *models.PasswordResetToken) error {
+	q := `INSERT INTO password_reset_tokens (user_id, hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;`
+
+	return s.db.QueryRowContext(ctx, q, token.UserID, token.Hash, token.CreatedAt, token.ExpiresAt).Scan(&token.ID)
+}
+
+// GetTokenByHash locks the token's row so it cannot be used twice concurrently.
+func (s *passwordResetStore) GetTokenByHash(ctx context.Context, hash []byte) (*models.PasswordResetToken, error) {
+	q := `SELECT id, user_id, hash, created_at, expires_at, used_at
+		FROM password_reset_tokens WHERE hash = $1
+		FOR UPDATE;`
+
+	token := new(models.PasswordResetToken)
+	err := s.db.QueryRowContext(ctx, q, hash).Scan(
+		&token.ID, &token.UserID, &token.Hash, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrPasswordResetTokenNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (s *passwordResetStore) MarkTokenUsed(ctx context.Context, id string, usedAt time.Time) error {
+	q := `UPDATE password_reset_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL;`
+
+	res, err := s.db.ExecContext(ctx, q, id, usedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrPasswordResetTokenNotFound
+	}
+
+	return nil
+}
+
+// InvalidateTokensByUserID uses up every outstanding token of the user so only the newest one sent works.
+func (s *passwordResetStore) InvalidateTokensByUserID(ctx context.Context, userID string, at time.Time) error {
+	q := `UPDATE password_reset_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL;`
+
+	_, err := s.db.ExecContext(ctx, q, userID, at)
+	return err
+}
+
+func (s *passwordResetStore) InsertRequest(ctx context.Context, request *models.PasswordResetRequest) error {
+	q := `INSERT INTO password_reset_requests (user_id, ip_address, requested_at)
+		VALUES ($1, $2, $3);`
+
+	_, err := s.db.ExecContext(ctx, q, request.UserID, request.IPAddress, request.RequestedAt)
+	return err
+}
+
+// GetRequestsByIPAddressSince counts the requests made from the IP address after since
+// and returns when the oldest of them was made, which is nil when there were none.
+func (s *passwordResetStore) GetRequestsByIPAddressSince(ctx context.Context, ipAddress string, since time.Time) (int, *time.Time, error) {
+	q := `SELECT count(*), MIN(requested_at) FROM password_reset_requests
+		WHERE ip_address = $1 AND requested_at > $2;`
+
+	var count int
+	var oldest *time.Time
+	if err := s.db.QueryRowContext(ctx, q, ipAddress, since).Scan(&count, &oldest); err != nil {
+		return 0, nil, err
+	}
+
+	return count, oldest, nil
+}
+
+func (s *passwordResetStore) CountRequestsByUserIDSince(ctx context.Context, userID string, since time.Time) (int, error) {
+	q := `SELECT count(*) FROM password_reset_requests
+		WHERE user_id = $1 AND requested_at > $2;`
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, q, userID, since).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
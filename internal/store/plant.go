@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+	"time"
 
 	"github.com/jasonuc/moota/internal/models"
 )
@@ -13,10 +14,15 @@ type PlantStore interface {
 	GetByOwnerID(context.Context, string, *GetPlantsOpts) ([]*models.Plant, error)
 	GetCountByUsername(context.Context, string) (*models.PlantCount, error)
 	GetBySoilIDAndProximity(context.Context, string, models.Coordinates, float64) ([]*models.Plant, error)
+	GetCirclesBySoilInProximity(context.Context, models.Coordinates, float64) (map[string][]models.CircleMeta, error)
 	GetByOwnerIDAndProximity(context.Context, string, models.Coordinates) ([]*models.Plant, error)
+	GetStaleForRefresh(context.Context, time.Time, int) ([]*models.Plant, error)
+	GetNearby(context.Context, string, models.Coordinates, float64, int, int) ([]*models.NearbyPlant, error)
 	Insert(context.Context, *models.Plant) error
 	Update(context.Context, *models.Plant) error
 	Delete(context.Context, string) error
+	IsHelper(context.Context, string, string) (bool, error)
+	SetHelpers(context.Context, string, []string) error
 }
 
 type plantStore struct {
@@ -25,6 +31,81 @@ type plantStore struct {
 
 type GetPlantsOpts struct {
 	IncludeDeceased bool
+	// ForUpdate locks the plants' rows until the transaction ends, so they can be changed without racing the refresh worker
+	ForUpdate bool
+}
+
+// GetStaleForRefresh locks and returns up to limit living plants that have not been refreshed since staleBefore, least recently refreshed first.
+// Plants already locked by another transaction are skipped so several workers can share the load.
+func (s *plantStore) GetStaleForRefresh(ctx context.Context, staleBefore time.Time, limit int) ([]*models.Plant, error) {
+	q := `SELECT p.id, p.nickname, p.hp, p.dead, p.owner_id, p.time_planted, p.last_watered_at, p.last_action_at, 
+         p.last_refreshed_at, p.grace_period_ends_at, ST_AsText(p.centre) as centre, p.radius_m, p.soil_id, 
+         p.optimal_soil, p.botanical_name, p.level, p.xp, p.woe, p.frolic, p.dread, p.malice, p.time_of_death,
+         p.last_fertilised_at, p.last_pruned_at, p.last_talked_to_at, p.care_permission, p.share_exact_location,
+         ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at
+		FROM plants p JOIN soils s ON p.soil_id = s.id
+		WHERE p.dead = false AND COALESCE(p.last_refreshed_at, p.time_planted) <= $1
+		ORDER BY COALESCE(p.last_refreshed_at, p.time_planted) ASC
+		LIMIT $2
+		FOR UPDATE OF p SKIP LOCKED;`
+
+	rows, err := s.db.QueryContext(ctx, q, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	return scanPlantsWithSoil(rows)
+}
+
+// GetNearby returns the living plants of players other than userID that are shown within radiusM of point, nearest first.
+// Plants are found by where they are shown to other players, so their exact location can't be narrowed down by changing the radius.
+func (s *plantStore) GetNearby(ctx context.Context, userID string, point models.Coordinates, radiusM float64, limit, offset int) ([]*models.NearbyPlant, error) {
+	q := `SELECT p.id, p.nickname, p.hp, p.dead, p.owner_id, p.time_planted, p.last_watered_at, p.last_action_at, 
+         p.last_refreshed_at, p.grace_period_ends_at, ST_AsText(p.centre) as centre, p.radius_m, p.soil_id, 
+         p.optimal_soil, p.botanical_name, p.level, p.xp, p.woe, p.frolic, p.dread, p.malice, p.time_of_death,
+         p.last_fertilised_at, p.last_pruned_at, p.last_talked_to_at, p.care_permission, p.share_exact_location,
+         ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at,
+         ST_AsText(p.shown_centre), ST_Distance(p.shown_centre, ST_SetSRID(ST_MakePoint($2, $3), 4326)::GEOGRAPHY) AS distance_m
+		FROM plants p JOIN soils s ON p.soil_id = s.id
+		WHERE p.dead = false AND p.owner_id <> $1
+		AND ST_DWithin(p.shown_centre, ST_SetSRID(ST_MakePoint($2, $3), 4326)::GEOGRAPHY, $4)
+		ORDER BY distance_m ASC, p.id ASC
+		LIMIT $5 OFFSET $6;`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, point.Lon, point.Lat, radiusM, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	plants := make([]*models.NearbyPlant, 0)
+	for rows.Next() {
+		var shownCentreText string
+		nearbyPlant := new(models.NearbyPlant)
+
+		plant, err := scanPlantWithSoil(rows, &shownCentreText, &nearbyPlant.DistanceM)
+		if err != nil {
+			return nil, err
+		}
+
+		nearbyPlant.Location, err = models.CoordinatesFromPostGIS(shownCentreText)
+		if err != nil {
+			return nil, err
+		}
+
+		nearbyPlant.Plant = *plant
+		nearbyPlant.ExactLocation = plant.ShareExactLocation
+		plants = append(plants, nearbyPlant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plants, nil
 }
 
 func (s *plantStore) GetCountByUsername(ctx context.Context, userID string) (*models.PlantCount, error) {
@@ -65,12 +146,14 @@ func (s *plantStore) GetCountByUsername(ctx context.Context, userID string) (*mo
 }
 
 func (s *plantStore) GetByOwnerIDAndProximity(ctx context.Context, ownerID string, point models.Coordinates) ([]*models.Plant, error) {
-	q := `SELECT id, nickname, hp, dead, owner_id, time_planted, last_watered_at, last_action_at, 
-         last_refreshed_at, grace_period_ends_at, ST_AsText(centre) as centre, radius_m, soil_id, 
-         optimal_soil, botanical_name, level, xp, woe, frolic, dread, malice, time_of_death 
-		FROM plants
-		WHERE owner_id = $1 AND dead = false
-		ORDER BY ST_Distance(centre, ST_SetSRID(ST_MakePoint($2, $3), 4326)::GEOGRAPHY) ASC;`
+	q := `SELECT p.id, p.nickname, p.hp, p.dead, p.owner_id, p.time_planted, p.last_watered_at, p.last_action_at, 
+         p.last_refreshed_at, p.grace_period_ends_at, ST_AsText(p.centre) as centre, p.radius_m, p.soil_id, 
+         p.optimal_soil, p.botanical_name, p.level, p.xp, p.woe, p.frolic, p.dread, p.malice, p.time_of_death,
+         p.last_fertilised_at, p.last_pruned_at, p.last_talked_to_at, p.care_permission, p.share_exact_location,
+         ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at
+		FROM plants p JOIN soils s ON p.soil_id = s.id
+		WHERE p.owner_id = $1 AND p.dead = false
+		ORDER BY ST_Distance(p.centre, ST_SetSRID(ST_MakePoint($2, $3), 4326)::GEOGRAPHY) ASC;`
 
 	rows, err := s.db.QueryContext(ctx, q, ownerID, point.Lon, point.Lat)
 	if err != nil {
@@ -79,74 +162,46 @@ func (s *plantStore) GetByOwnerIDAndProximity(ctx context.Context, ownerID strin
 	//nolint:errcheck
 	defer rows.Close()
 
-	plants := make([]*models.Plant, 0)
-	for rows.Next() {
-		var centreText string
-		var radiusM float64
-
-		plant := new(models.Plant)
-		plant.Soil = new(models.Soil)
-		plant.Tempers = new(models.Tempers)
-		err := rows.Scan(
-			&plant.ID, &plant.Nickname, &plant.Hp, &plant.Dead, &plant.OwnerID,
-			&plant.TimePlanted, &plant.LastWateredAt, &plant.LastActionAt,
-			&plant.LastRefreshedAt, &plant.GracePeriodEndsAt, &centreText,
-			&radiusM, &plant.Soil.ID, &plant.OptimalSoil, &plant.BotanicalName, &plant.Level, &plant.XP,
-			&plant.Tempers.Woe, &plant.Tempers.Frolic, &plant.Tempers.Dread, &plant.Tempers.Malice, &plant.TimeOfDeath,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		centre, err := models.CoordinatesFromPostGIS(centreText)
-		if err != nil {
-			return nil, err
-		}
-
-		plant.CircleMeta = models.NewCircleMeta(centre, radiusM)
+	return scanPlantsWithSoil(rows)
+}
 
-		plants = append(plants, plant)
-	}
+func (s *plantStore) GetBySoilIDAndProximity(ctx context.Context, soilID string, point models.Coordinates, distanceM float64) ([]*models.Plant, error) {
+	q := `SELECT p.id, p.nickname, p.hp, p.dead, p.owner_id, p.time_planted, p.last_watered_at, p.last_action_at, 
+         p.last_refreshed_at, p.grace_period_ends_at, ST_AsText(p.centre) as centre, p.radius_m, p.soil_id, 
+         p.optimal_soil, p.botanical_name, p.level, p.xp, p.woe, p.frolic, p.dread, p.malice, p.time_of_death,
+         p.last_fertilised_at, p.last_pruned_at, p.last_talked_to_at, p.care_permission, p.share_exact_location,
+         ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at
+		FROM plants p JOIN soils s ON p.soil_id = s.id
+		WHERE p.soil_id = $1 AND p.dead = false 
+		AND ST_DWithin(p.centre, ST_SetSRID(ST_MakePoint($2, $3), 4326)::GEOGRAPHY, $4);`
 
-	if err := rows.Err(); err != nil {
+	rows, err := s.db.QueryContext(ctx, q, soilID, point.Lon, point.Lat, distanceM)
+	if err != nil {
 		return nil, err
 	}
+	//nolint:errcheck
+	defer rows.Close()
 
-	return plants, nil
+	return scanPlantsWithSoil(rows)
 }
 
-func (s *plantStore) GetBySoilIDAndProximity(ctx context.Context, soilID string, point models.Coordinates, distanceM float64) ([]*models.Plant, error) {
-	q := `SELECT id, nickname, hp, dead, owner_id, time_planted, last_watered_at, last_action_at, 
-         last_refreshed_at, grace_period_ends_at, ST_AsText(centre) as centre, radius_m, soil_id, 
-         optimal_soil, botanical_name, level, xp, woe, frolic, dread, malice, time_of_death 
-		FROM plants
-		WHERE soil_id = $1 AND dead = false 
-		AND ST_DWithin(centre, ST_SetSRID(ST_MakePoint($2, $3), 4326)::GEOGRAPHY, $4);`
+// GetCirclesBySoilInProximity returns the circles of the living plants within distanceM of point, grouped by the ID of their soil.
+func (s *plantStore) GetCirclesBySoilInProximity(ctx context.Context, point models.Coordinates, distanceM float64) (map[string][]models.CircleMeta, error) {
+	q := `SELECT p.soil_id, ST_AsText(p.centre), p.radius_m FROM plants p
+		WHERE p.dead = false AND ST_DWithin(p.centre, ST_SetSRID(ST_MakePoint($1, $2), 4326)::GEOGRAPHY, $3);`
 
-	rows, err := s.db.QueryContext(ctx, q, soilID, point.Lon, point.Lat, distanceM)
+	rows, err := s.db.QueryContext(ctx, q, point.Lon, point.Lat, distanceM)
 	if err != nil {
 		return nil, err
 	}
 	//nolint:errcheck
 	defer rows.Close()
 
-	plants := make([]*models.Plant, 0)
+	circles := make(map[string][]models.CircleMeta)
 	for rows.Next() {
-		var centreText string
+		var soilID, centreText string
 		var radiusM float64
-
-		plant := new(models.Plant)
-		plant.Soil = new(models.Soil)
-		plant.Tempers = new(models.Tempers)
-
-		err := rows.Scan(
-			&plant.ID, &plant.Nickname, &plant.Hp, &plant.Dead, &plant.OwnerID,
-			&plant.TimePlanted, &plant.LastWateredAt, &plant.LastActionAt,
-			&plant.LastRefreshedAt, &plant.GracePeriodEndsAt, &centreText,
-			&radiusM, &plant.Soil.ID, &plant.OptimalSoil, &plant.BotanicalName, &plant.Level, &plant.XP,
-			&plant.Tempers.Woe, &plant.Tempers.Frolic, &plant.Tempers.Dread, &plant.Tempers.Malice, &plant.TimeOfDeath,
-		)
-		if err != nil {
+		if err := rows.Scan(&soilID, &centreText, &radiusM); err != nil {
 			return nil, err
 		}
 
@@ -155,27 +210,31 @@ func (s *plantStore) GetBySoilIDAndProximity(ctx context.Context, soilID string,
 			return nil, err
 		}
 
-		plant.CircleMeta = models.NewCircleMeta(centre, radiusM)
-
-		plants = append(plants, plant)
+		circles[soilID] = append(circles[soilID], models.NewCircleMeta(centre, radiusM))
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return plants, nil
+	return circles, nil
 }
 
 func (s *plantStore) GetByOwnerID(ctx context.Context, ownerID string, opts *GetPlantsOpts) ([]*models.Plant, error) {
-	q := `SELECT id, nickname, hp, dead, owner_id, time_planted, last_watered_at, 
-         last_action_at, last_refreshed_at, grace_period_ends_at, ST_AsText(centre) as centre, 
-         radius_m, soil_id, optimal_soil, botanical_name, level, xp, woe, frolic, dread, malice, time_of_death 
-		FROM plants
-		WHERE owner_id = $1`
+	q := `SELECT p.id, p.nickname, p.hp, p.dead, p.owner_id, p.time_planted, p.last_watered_at, 
+         p.last_action_at, p.last_refreshed_at, p.grace_period_ends_at, ST_AsText(p.centre) as centre, 
+         p.radius_m, p.soil_id, p.optimal_soil, p.botanical_name, p.level, p.xp, p.woe, p.frolic, p.dread, p.malice, p.time_of_death,
+         p.last_fertilised_at, p.last_pruned_at, p.last_talked_to_at, p.care_permission, p.share_exact_location,
+         ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at
+		FROM plants p JOIN soils s ON p.soil_id = s.id
+		WHERE p.owner_id = $1`
 
 	if !opts.IncludeDeceased {
-		q += ` AND dead = false`
+		q += ` AND p.dead = false`
+	}
+
+	if opts.ForUpdate {
+		q += ` ORDER BY p.id FOR UPDATE OF p`
 	}
 
 	rows, err := s.db.QueryContext(ctx, q, ownerID)
@@ -185,41 +244,7 @@ func (s *plantStore) GetByOwnerID(ctx context.Context, ownerID string, opts *Get
 	//nolint:errcheck
 	defer rows.Close()
 
-	plants := make([]*models.Plant, 0)
-	for rows.Next() {
-		var centreText string
-		var radiusM float64
-
-		plant := new(models.Plant)
-		plant.Soil = new(models.Soil)
-		plant.Tempers = new(models.Tempers)
-
-		err := rows.Scan(
-			&plant.ID, &plant.Nickname, &plant.Hp, &plant.Dead, &plant.OwnerID,
-			&plant.TimePlanted, &plant.LastWateredAt, &plant.LastActionAt,
-			&plant.LastRefreshedAt, &plant.GracePeriodEndsAt, &centreText,
-			&radiusM, &plant.Soil.ID, &plant.OptimalSoil, &plant.BotanicalName, &plant.Level, &plant.XP,
-			&plant.Tempers.Woe, &plant.Tempers.Frolic, &plant.Tempers.Dread, &plant.Tempers.Malice, &plant.TimeOfDeath,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		centre, err := models.CoordinatesFromPostGIS(centreText)
-		if err != nil {
-			return nil, err
-		}
-
-		plant.CircleMeta = models.NewCircleMeta(centre, radiusM)
-
-		plants = append(plants, plant)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return plants, nil
+	return scanPlantsWithSoil(rows)
 }
 
 func (s *plantStore) Get(ctx context.Context, id string, opts *GetPlantsOpts) (*models.Plant, error) {
@@ -227,9 +252,14 @@ func (s *plantStore) Get(ctx context.Context, id string, opts *GetPlantsOpts) (*
 			p.id, p.nickname, p.hp, p.dead, p.owner_id, p.time_planted, p.last_watered_at, p.last_action_at, 
 			p.last_refreshed_at, p.grace_period_ends_at, ST_AsText(p.centre), 
 			p.radius_m, p.soil_id, p.optimal_soil, p.botanical_name, p.level, p.xp, p.woe, p.frolic, p.dread, p.malice, 
-			ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at, p.time_of_death
+			ST_AsText(s.centre), s.radius_m, s.soil_type, s.water_retention, s.nutrient_richness, s.created_at, p.time_of_death,
+			p.last_fertilised_at, p.last_pruned_at, p.last_talked_to_at, p.care_permission, p.share_exact_location
 			FROM plants p JOIN soils s ON p.soil_id = s.id
-			WHERE p.id = $1 AND (dead = false OR dead = $2);`
+			WHERE p.id = $1 AND (dead = false OR dead = $2)`
+
+	if opts.ForUpdate {
+		q += ` FOR UPDATE OF p`
+	}
 
 	var plantCentreText string
 	var plantRadiusM float64
@@ -247,6 +277,7 @@ func (s *plantStore) Get(ctx context.Context, id string, opts *GetPlantsOpts) (*
 		&plantRadiusM, &plant.Soil.ID, &plant.OptimalSoil, &plant.BotanicalName, &plant.Level, &plant.XP,
 		&plant.Tempers.Woe, &plant.Tempers.Frolic, &plant.Tempers.Dread, &plant.Tempers.Malice,
 		&soilCentreText, &soilRadiusM, &plant.Soil.Type, &plant.Soil.WaterRetention, &plant.Soil.NutrientRichness, &plant.Soil.CreatedAt, &plant.TimeOfDeath,
+		&plant.LastFertilisedAt, &plant.LastPrunedAt, &plant.LastTalkedToAt, &plant.CarePermission, &plant.ShareExactLocation,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows || strings.Contains(err.Error(), ErrInvalidUUIDSyntax) {
@@ -271,9 +302,11 @@ func (s *plantStore) Get(ctx context.Context, id string, opts *GetPlantsOpts) (*
 }
 
 func (s *plantStore) Insert(ctx context.Context, plant *models.Plant) error {
-	q := `INSERT INTO plants (nickname, hp, owner_id, centre, radius_m, soil_id, optimal_soil, botanical_name, level, xp, woe, frolic, dread, malice, last_refreshed_at, grace_period_ends_at)
-			VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
-			RETURNING id, dead, time_planted, last_watered_at, last_action_at;`
+	q := `INSERT INTO plants (nickname, hp, owner_id, centre, radius_m, soil_id, optimal_soil, botanical_name, level, xp, woe, frolic, dread, malice,
+				time_planted, last_watered_at, last_action_at, last_refreshed_at, grace_period_ends_at, care_permission, share_exact_location, fuzzed_centre)
+			VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint($4, $5), 4326), $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+				ST_Project(ST_SetSRID(ST_MakePoint($4, $5), 4326)::GEOGRAPHY, $23 * sqrt(random()), 2 * pi() * random()))
+			RETURNING id, dead;`
 
 	err := s.db.QueryRowContext(ctx,
 		q,
@@ -282,9 +315,11 @@ func (s *plantStore) Insert(ctx context.Context, plant *models.Plant) error {
 		plant.Soil.ID, plant.OptimalSoil, plant.BotanicalName,
 		plant.Level, plant.XP,
 		plant.Tempers.Woe, plant.Tempers.Frolic, plant.Tempers.Dread, plant.Tempers.Malice,
-		plant.LastRefreshedAt, plant.GracePeriodEndsAt,
+		plant.TimePlanted, plant.LastWateredAt, plant.LastActionAt,
+		plant.LastRefreshedAt, plant.GracePeriodEndsAt, plant.CarePermission, plant.ShareExactLocation,
+		models.PlantLocationFuzzM,
 	).Scan(
-		&plant.ID, &plant.Dead, &plant.TimePlanted, &plant.LastWateredAt, &plant.LastActionAt,
+		&plant.ID, &plant.Dead,
 	)
 
 	if err != nil {
@@ -299,14 +334,20 @@ func (s *plantStore) Update(ctx context.Context, plant *models.Plant) error {
           SET nickname = $1, hp = $2, dead = $3, 
               level = $4, xp = $5,
               last_action_at = $6, last_watered_at = $7, time_of_death = $8,
-              last_refreshed_at = $9, grace_period_ends_at = $10
-          WHERE id = $11;`
+              last_refreshed_at = $9, grace_period_ends_at = $10,
+              last_fertilised_at = $11, last_pruned_at = $12, last_talked_to_at = $13,
+              woe = $14, frolic = $15, dread = $16, malice = $17,
+              care_permission = $18, share_exact_location = $19
+          WHERE id = $20;`
 
 	res, err := s.db.ExecContext(ctx, q,
 		plant.Nickname, plant.Hp, plant.Dead,
 		plant.Level, plant.XP,
 		plant.LastActionAt, plant.LastWateredAt, plant.TimeOfDeath,
 		plant.LastRefreshedAt, plant.GracePeriodEndsAt,
+		plant.LastFertilisedAt, plant.LastPrunedAt, plant.LastTalkedToAt,
+		plant.Tempers.Woe, plant.Tempers.Frolic, plant.Tempers.Dread, plant.Tempers.Malice,
+		plant.CarePermission, plant.ShareExactLocation,
 		plant.ID)
 	if err != nil {
 		return err
@@ -344,3 +385,93 @@ func (s *plantStore) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (s *plantStore) IsHelper(ctx context.Context, plantID, userID string) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM plant_helpers WHERE plant_id = $1 AND user_id = $2);`
+
+	var isHelper bool
+	if err := s.db.QueryRowContext(ctx, q, plantID, userID).Scan(&isHelper); err != nil {
+		return false, err
+	}
+
+	return isHelper, nil
+}
+
+// SetHelpers replaces the plant's helpers with the given users.
+func (s *plantStore) SetHelpers(ctx context.Context, plantID string, userIDs []string) error {
+	q := `DELETE FROM plant_helpers WHERE plant_id = $1;`
+	if _, err := s.db.ExecContext(ctx, q, plantID); err != nil {
+		return err
+	}
+
+	q = `INSERT INTO plant_helpers (plant_id, user_id) VALUES ($1, $2)
+			ON CONFLICT (plant_id, user_id) DO NOTHING;`
+	for _, userID := range userIDs {
+		if _, err := s.db.ExecContext(ctx, q, plantID, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanPlantsWithSoil scans rows selecting the plant columns followed by the columns of the plant's soil.
+func scanPlantsWithSoil(rows *sql.Rows) ([]*models.Plant, error) {
+	plants := make([]*models.Plant, 0)
+	for rows.Next() {
+		plant, err := scanPlantWithSoil(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		plants = append(plants, plant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plants, nil
+}
+
+// scanPlantWithSoil scans a row selecting the plant columns followed by the columns of the plant's soil, and then into extra.
+func scanPlantWithSoil(row rowScanner, extra ...any) (*models.Plant, error) {
+	var centreText string
+	var radiusM float64
+
+	var soilCentreText string
+	var soilRadiusM float64
+
+	plant := new(models.Plant)
+	plant.Soil = new(models.Soil)
+	plant.Tempers = new(models.Tempers)
+
+	dest := []any{
+		&plant.ID, &plant.Nickname, &plant.Hp, &plant.Dead, &plant.OwnerID,
+		&plant.TimePlanted, &plant.LastWateredAt, &plant.LastActionAt,
+		&plant.LastRefreshedAt, &plant.GracePeriodEndsAt, &centreText,
+		&radiusM, &plant.Soil.ID, &plant.OptimalSoil, &plant.BotanicalName, &plant.Level, &plant.XP,
+		&plant.Tempers.Woe, &plant.Tempers.Frolic, &plant.Tempers.Dread, &plant.Tempers.Malice, &plant.TimeOfDeath,
+		&plant.LastFertilisedAt, &plant.LastPrunedAt, &plant.LastTalkedToAt, &plant.CarePermission, &plant.ShareExactLocation,
+		&soilCentreText, &soilRadiusM, &plant.Soil.Type, &plant.Soil.WaterRetention, &plant.Soil.NutrientRichness, &plant.Soil.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	centre, err := models.CoordinatesFromPostGIS(centreText)
+	if err != nil {
+		return nil, err
+	}
+
+	plant.CircleMeta = models.NewCircleMeta(centre, radiusM)
+
+	soilCentre, err := models.CoordinatesFromPostGIS(soilCentreText)
+	if err != nil {
+		return nil, err
+	}
+
+	plant.Soil.CircleMeta = models.NewCircleMeta(soilCentre, soilRadiusM)
+
+	return plant, nil
+}
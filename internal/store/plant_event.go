@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+)
+
+type PlantEventStore interface {
+	GetByPlantID(context.Context, string, int, int) ([]*models.PlantEvent, error)
+	Insert(context.Context, *models.PlantEvent) error
+	CountCommunityWateringsByActorID(context.Context, string, time.Time) (int, error)
+}
+
+type plantEventStore struct {
+	db Querier
+}
+
+// GetByPlantID returns up to limit of the plant's events after skipping offset, most recent first.
+func (s *plantEventStore) GetByPlantID(ctx context.Context, plantID string, limit, offset int) ([]*models.PlantEvent, error) {
+	q := `SELECT id, plant_id, event_type, action, actor_id, ST_AsText(location), hp_delta, xp_delta, level, occurred_at
+			FROM plant_events
+			WHERE plant_id = $1
+			ORDER BY occurred_at DESC, id DESC
+			LIMIT $2 OFFSET $3;`
+
+	rows, err := s.db.QueryContext(ctx, q, plantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	events := make([]*models.PlantEvent, 0)
+	for rows.Next() {
+		var action sql.NullInt16
+		var level sql.NullInt64
+		var locationText sql.NullString
+		event := new(models.PlantEvent)
+
+		err := rows.Scan(
+			&event.ID, &event.PlantID, &event.Type, &action, &event.ActorID, &locationText,
+			&event.HpDelta, &event.XpDelta, &level, &event.OccurredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Action = models.PlantAction(action.Int16)
+		event.Level = level.Int64
+
+		if locationText.Valid {
+			location, err := models.CoordinatesFromPostGIS(locationText.String)
+			if err != nil {
+				return nil, err
+			}
+			event.Location = &location
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *plantEventStore) Insert(ctx context.Context, event *models.PlantEvent) error {
+	q := `INSERT INTO plant_events (plant_id, event_type, action, actor_id, location, hp_delta, xp_delta, level, occurred_at)
+			VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5, $6), 4326), $7, $8, $9, $10)
+			RETURNING id;`
+
+	var action sql.NullInt16
+	if event.Action != 0 {
+		action = sql.NullInt16{Int16: int16(event.Action), Valid: true}
+	}
+
+	var level sql.NullInt64
+	if event.Level != 0 {
+		level = sql.NullInt64{Int64: event.Level, Valid: true}
+	}
+
+	var lon, lat *float64
+	if event.Location != nil {
+		lon, lat = &event.Location.Lon, &event.Location.Lat
+	}
+
+	return s.db.QueryRowContext(ctx, q,
+		event.PlantID, event.Type, action, event.ActorID, lon, lat,
+		event.HpDelta, event.XpDelta, level, event.OccurredAt,
+	).Scan(&event.ID)
+}
+
+// CountCommunityWateringsByActorID counts the waterings the player has done since the given time on plants they do not own.
+func (s *plantEventStore) CountCommunityWateringsByActorID(ctx context.Context, actorID string, since time.Time) (int, error) {
+	q := `SELECT count(*) FROM plant_events e
+			JOIN plants p ON e.plant_id = p.id
+			WHERE e.actor_id = $1 AND e.event_type = $2 AND e.action = $3
+			AND p.owner_id <> e.actor_id AND e.occurred_at > $4;`
+
+	var count int
+	err := s.db.QueryRowContext(ctx, q, actorID, models.PlantEventAction, int16(models.PlantActionWater), since).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantEventStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping plant event store integration tests")
+	}
+
+	ctx := context.Background()
+	pgContainer, err := createPostgresContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := pgContainer.Terminate(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	db, err := openDB(pgContainer.connectionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := db.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	migrationsPath := filepath.Join("..", "..", "migrations")
+	err = applyMigrations(db, migrationsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initScriptPath := filepath.Join("testdata", "plant_event_store_init.sql")
+	initSQL, err := os.ReadFile(initScriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(string(initSQL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &plantEventStore{db: db}
+
+	plantID := "00000000-0000-4000-d000-000000000001"
+	actorID := "00000000-0000-4000-a000-000000000001"
+	baseTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Insert", func(t *testing.T) {
+		events := []*models.PlantEvent{
+			{PlantID: plantID, Type: models.PlantEventDecay, HpDelta: -1, OccurredAt: baseTime},
+			{PlantID: plantID, Type: models.PlantEventDecay, HpDelta: -1, OccurredAt: baseTime.Add(4 * time.Hour)},
+			{
+				PlantID: plantID, Type: models.PlantEventAction, Action: models.PlantActionWater,
+				ActorID: &actorID, Location: &models.Coordinates{Lat: 40.782865, Lon: -73.965355},
+				HpDelta: 5, XpDelta: 30, OccurredAt: baseTime.Add(5 * time.Hour),
+			},
+		}
+
+		for _, event := range events {
+			err := store.Insert(ctx, event)
+			assert.NoError(t, err, "unexpected error")
+			assert.NotZero(t, event.ID, "expected event ID to be set")
+		}
+	})
+
+	t.Run("GetByPlantID_MostRecentFirst", func(t *testing.T) {
+		events, err := store.GetByPlantID(ctx, plantID, 10, 0)
+		assert.NoError(t, err, "unexpected error")
+		assert.Len(t, events, 3, "expected 3 events")
+
+		if len(events) == 3 {
+			assert.Equal(t, models.PlantEventAction, events[0].Type)
+			assert.Equal(t, models.PlantActionWater, events[0].Action)
+			assert.Equal(t, actorID, *events[0].ActorID)
+			assert.Equal(t, 40.782865, events[0].Location.Lat)
+			assert.Nil(t, events[1].ActorID)
+			assert.Nil(t, events[1].Location)
+		}
+	})
+
+	t.Run("GetByPlantID_Paged", func(t *testing.T) {
+		events, err := store.GetByPlantID(ctx, plantID, 2, 2)
+		assert.NoError(t, err, "unexpected error")
+		assert.Len(t, events, 1, "expected 1 event on the second page")
+	})
+}
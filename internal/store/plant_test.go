@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantStoreNearby(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping plant store integration tests")
+	}
+
+	ctx := context.Background()
+	pgContainer, err := createPostgresContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := pgContainer.Terminate(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	db, err := openDB(pgContainer.connectionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := db.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	migrationsPath := filepath.Join("..", "..", "migrations")
+	err = applyMigrations(db, migrationsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initScriptPath := filepath.Join("testdata", "plant_store_init.sql")
+	initSQL, err := os.ReadFile(initScriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(string(initSQL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &plantStore{db: db}
+
+	viewerID := "00000000-0000-4000-a000-000000000001"
+	hiddenPlantID := "00000000-0000-4000-d000-000000000001"
+	sharedPlantID := "00000000-0000-4000-d000-000000000002"
+	point := models.Coordinates{Lat: 40.782865, Lon: -73.965355}
+
+	t.Run("GetNearby_OtherPlayersLivingPlantsNearestFirst", func(t *testing.T) {
+		plants, err := store.GetNearby(ctx, viewerID, point, 1000, 10, 0)
+		assert.NoError(t, err, "unexpected error")
+		assert.Len(t, plants, 2, "expected the hidden and the shared plant")
+
+		if len(plants) == 2 {
+			assert.Equal(t, sharedPlantID, plants[0].ID)
+			assert.True(t, plants[0].ExactLocation)
+			assert.Equal(t, plants[0].Centre(), plants[0].Location)
+
+			assert.Equal(t, hiddenPlantID, plants[1].ID)
+			assert.False(t, plants[1].ExactLocation)
+			assert.Equal(t, models.Coordinates{Lat: 40.783265, Lon: -73.965355}, plants[1].Location)
+			assert.InDelta(t, 44, plants[1].DistanceM, 1, "distance should be measured from where the plant is shown")
+		}
+	})
+
+	t.Run("GetNearby_UsesTheShownLocation", func(t *testing.T) {
+		plants, err := store.GetNearby(ctx, viewerID, point, 20, 10, 0)
+		assert.NoError(t, err, "unexpected error")
+		assert.Len(t, plants, 1, "the hidden plant is shown further away than 20m")
+
+		if len(plants) == 1 {
+			assert.Equal(t, sharedPlantID, plants[0].ID)
+		}
+	})
+
+	t.Run("GetNearby_Paged", func(t *testing.T) {
+		plants, err := store.GetNearby(ctx, viewerID, point, 1000, 1, 1)
+		assert.NoError(t, err, "unexpected error")
+		assert.Len(t, plants, 1, "expected 1 plant on the second page")
+
+		if len(plants) == 1 {
+			assert.Equal(t, hiddenPlantID, plants[0].ID)
+		}
+	})
+
+	t.Run("GetCirclesBySoilInProximity_LivingPlantsOnly", func(t *testing.T) {
+		circles, err := store.GetCirclesBySoilInProximity(ctx, point, 1000)
+		assert.NoError(t, err, "unexpected error")
+		assert.Len(t, circles, 1, "the far away soil should not be included")
+		assert.Len(t, circles["00000000-0000-4000-c000-000000000101"], 3, "the dead plant should not be included")
+	})
+
+	t.Run("Insert_FuzzesTheShownLocation", func(t *testing.T) {
+		now := time.Now()
+		plant := &models.Plant{
+			Nickname:       "New Sprout",
+			Hp:             90,
+			OwnerID:        viewerID,
+			Soil:           &models.Soil{ID: "00000000-0000-4000-c000-000000000101"},
+			Tempers:        models.NewTempers(),
+			LevelMeta:      models.NewLeveLMeta(1, 0),
+			CarePermission: models.CarePermissionOwner,
+			SeedMeta:       models.SeedMeta{OptimalSoil: models.SoilTypeLoam, BotanicalName: "Quercus alba"},
+			CircleMeta:     models.NewCircleMeta(point, models.PlantInteractionRadius),
+			TimePlanted:    now,
+			LastWateredAt:  now,
+			LastActionAt:   now,
+		}
+		assert.NoError(t, store.Insert(ctx, plant), "unexpected error")
+
+		var fuzzM float64
+		err := db.QueryRow(`SELECT ST_Distance(centre, fuzzed_centre) FROM plants WHERE id = $1;`, plant.ID).Scan(&fuzzM)
+		assert.NoError(t, err, "unexpected error")
+		assert.LessOrEqual(t, fuzzM, float64(models.PlantLocationFuzzM)+1)
+	})
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/jasonuc/moota/internal/models"
 )
@@ -10,6 +12,10 @@ type RefreshTokenStore interface {
 	Insert(context.Context, *models.RefreshToken) error
 	GetByHash(context.Context, []byte) (*models.RefreshToken, error)
 	Revoke(context.Context, string) error
+	RevokeFamily(context.Context, string) error
+	GetSessionsByUserID(context.Context, string, time.Time) ([]*models.Session, error)
+	RevokeSession(context.Context, string, string) error
+	RevokeOtherSessions(context.Context, string, string) error
 	RevokeManyByUserID(context.Context, string) error
 }
 
@@ -17,14 +23,21 @@ type refreshTokenStore struct {
 	db Querier
 }
 
+// Insert stores the refresh token. A token without a family starts a new one, and so a new session.
 func (s *refreshTokenStore) Insert(ctx context.Context, refreshToken *models.RefreshToken) error {
-	q := `INSERT INTO refresh_tokens (user_id, hash, created_at, expires_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, revoked_at;`
+	if refreshToken.SessionCreatedAt.IsZero() {
+		refreshToken.SessionCreatedAt = refreshToken.CreatedAt
+	}
+
+	q := `INSERT INTO refresh_tokens (user_id, hash, created_at, expires_at, family_id, parent_id, session_created_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, COALESCE($5::UUID, gen_random_uuid()), $6, $7, $8, $9)
+		RETURNING id, family_id, revoked_at;`
 
 	err := s.db.QueryRowContext(
 		ctx, q, refreshToken.UserID, refreshToken.Hash, refreshToken.CreatedAt, refreshToken.ExpiresAt,
-	).Scan(&refreshToken.ID, &refreshToken.RevokedAt)
+		nullIfEmpty(refreshToken.FamilyID), refreshToken.ParentID, refreshToken.SessionCreatedAt,
+		refreshToken.Client.UserAgent, refreshToken.Client.IPAddress,
+	).Scan(&refreshToken.ID, &refreshToken.FamilyID, &refreshToken.RevokedAt)
 
 	if err != nil {
 		return err
@@ -33,14 +46,17 @@ func (s *refreshTokenStore) Insert(ctx context.Context, refreshToken *models.Ref
 	return nil
 }
 
+// GetByHash locks the token's row so concurrent refreshes with the same token are serialised.
 func (s *refreshTokenStore) GetByHash(ctx context.Context, refreshTokenHash []byte) (*models.RefreshToken, error) {
-	q := `SELECT id, user_id, hash, created_at, expires_at, revoked_at
-		FROM refresh_tokens WHERE hash = $1;`
+	q := `SELECT id, user_id, family_id, parent_id, hash, created_at, expires_at, revoked_at, session_created_at, user_agent, ip_address
+		FROM refresh_tokens WHERE hash = $1
+		FOR UPDATE;`
 
 	refreshToken := new(models.RefreshToken)
 	err := s.db.QueryRowContext(ctx, q, refreshTokenHash).Scan(
-		&refreshToken.ID, &refreshToken.UserID, &refreshToken.Hash,
-		&refreshToken.CreatedAt, &refreshToken.ExpiresAt, &refreshToken.RevokedAt,
+		&refreshToken.ID, &refreshToken.UserID, &refreshToken.FamilyID, &refreshToken.ParentID, &refreshToken.Hash,
+		&refreshToken.CreatedAt, &refreshToken.ExpiresAt, &refreshToken.RevokedAt, &refreshToken.SessionCreatedAt,
+		&refreshToken.Client.UserAgent, &refreshToken.Client.IPAddress,
 	)
 
 	if err != nil {
@@ -70,11 +86,56 @@ func (s *refreshTokenStore) Revoke(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *refreshTokenStore) RevokeManyByUserID(ctx context.Context, id string) error {
-	q := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1;`
+// RevokeFamily revokes every token in the family that has not already been revoked.
+func (s *refreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	q := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL;`
 
-	res, err := s.db.ExecContext(ctx, q, id)
+	_, err := s.db.ExecContext(ctx, q, familyID)
+	return err
+}
+
+// GetSessionsByUserID lists the user's sessions that still have a valid refresh token as of now, most recently used first.
+func (s *refreshTokenStore) GetSessionsByUserID(ctx context.Context, userID string, now time.Time) ([]*models.Session, error) {
+	q := `SELECT family_id, session_created_at, created_at, expires_at, user_agent, ip_address
+		FROM refresh_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC;`
+
+	rows, err := s.db.QueryContext(ctx, q, userID, now)
 	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	sessions := make([]*models.Session, 0)
+	for rows.Next() {
+		session := new(models.Session)
+		if err := rows.Scan(
+			&session.ID, &session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.UserAgent, &session.IPAddress,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the user's session with the given ID.
+func (s *refreshTokenStore) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	q := `UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL;`
+
+	res, err := s.db.ExecContext(ctx, q, userID, sessionID)
+	if err != nil {
+		if strings.Contains(err.Error(), ErrInvalidUUIDSyntax) {
+			return models.ErrSessionNotFound
+		}
 		return err
 	}
 
@@ -84,8 +145,26 @@ func (s *refreshTokenStore) RevokeManyByUserID(ctx context.Context, id string) e
 	}
 
 	if rowsAffected == 0 {
-		return models.ErrUserNotFound
+		return models.ErrSessionNotFound
 	}
 
 	return nil
 }
+
+// RevokeOtherSessions revokes every one of the user's sessions except the one with the given ID.
+func (s *refreshTokenStore) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error {
+	q := `UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL;`
+
+	_, err := s.db.ExecContext(ctx, q, userID, keepSessionID)
+	return err
+}
+
+// RevokeManyByUserID revokes every one of the user's refresh tokens that has not already been revoked.
+// A user without any is not an error.
+func (s *refreshTokenStore) RevokeManyByUserID(ctx context.Context, id string) error {
+	q := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`
+
+	_, err := s.db.ExecContext(ctx, q, id)
+	return err
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jasonuc/moota/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshTokenStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping refresh token store integration tests")
+	}
+
+	ctx := context.Background()
+	pgContainer, err := createPostgresContainer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := pgContainer.Terminate(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	db, err := openDB(pgContainer.connectionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := db.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	migrationsPath := filepath.Join("..", "..", "migrations")
+	err = applyMigrations(db, migrationsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initScriptPath := filepath.Join("testdata", "refresh_token_store_init.sql")
+	initSQL, err := os.ReadFile(initScriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(string(initSQL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &refreshTokenStore{db: db}
+
+	userID := "00000000-0000-4000-a000-000000000001"
+	now := time.Now()
+	newToken := func(hash string) *models.RefreshToken {
+		return &models.RefreshToken{
+			UserID:    userID,
+			Hash:      []byte(hash),
+			CreatedAt: now,
+			ExpiresAt: now.Add(time.Hour),
+		}
+	}
+
+	root := newToken("root")
+	child := newToken("child")
+	unrelated := newToken("unrelated")
+
+	t.Run("Insert_StartsNewFamily", func(t *testing.T) {
+		err := store.Insert(ctx, root)
+		assert.NoError(t, err, "unexpected error")
+		assert.NotEmpty(t, root.FamilyID, "expected a family to be assigned")
+
+		err = store.Insert(ctx, unrelated)
+		assert.NoError(t, err, "unexpected error")
+		assert.NotEqual(t, root.FamilyID, unrelated.FamilyID, "expected separate logins to have separate families")
+	})
+
+	t.Run("Insert_JoinsFamily", func(t *testing.T) {
+		child.FamilyID = root.FamilyID
+		child.ParentID = &root.ID
+
+		err := store.Insert(ctx, child)
+		assert.NoError(t, err, "unexpected error")
+		assert.Equal(t, root.FamilyID, child.FamilyID)
+
+		got, err := store.GetByHash(ctx, []byte("child"))
+		assert.NoError(t, err, "unexpected error")
+		assert.Equal(t, root.ID, *got.ParentID)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		err := store.Revoke(ctx, root.ID)
+		assert.NoError(t, err, "unexpected error")
+
+		got, err := store.GetByHash(ctx, []byte("root"))
+		assert.NoError(t, err, "unexpected error")
+		assert.NotNil(t, got.RevokedAt, "expected root to be revoked")
+
+		got, err = store.GetByHash(ctx, []byte("child"))
+		assert.NoError(t, err, "unexpected error")
+		assert.Nil(t, got.RevokedAt, "expected child to still be valid")
+	})
+
+	t.Run("RevokeFamily_OnReuse", func(t *testing.T) {
+		err := store.RevokeFamily(ctx, root.FamilyID)
+		assert.NoError(t, err, "unexpected error")
+
+		got, err := store.GetByHash(ctx, []byte("child"))
+		assert.NoError(t, err, "unexpected error")
+		assert.NotNil(t, got.RevokedAt, "expected the whole family to be revoked")
+
+		got, err = store.GetByHash(ctx, []byte("unrelated"))
+		assert.NoError(t, err, "unexpected error")
+		assert.Nil(t, got.RevokedAt, "expected other families to be untouched")
+	})
+}
@@ -12,10 +12,12 @@ import (
 type SeedStore interface {
 	Get(context.Context, string) (*models.Seed, error)
 	GetByOwnerID(context.Context, string) ([]*models.Seed, error)
+	GetAllByOwnerID(context.Context, string) ([]*models.Seed, error)
 	GetCountByUsername(context.Context, string) (*models.SeedCount, error)
 	GetLastFulfilledSeedRequestTimeByUserID(context.Context, string) (time.Time, error)
 	Insert(context.Context, *models.Seed) error
 	InsertSeedRequest(context.Context, string, time.Time, bool, int) error
+	GetSeedRequestsByUserID(context.Context, string) ([]*models.SeedRequest, error)
 	MarkAsPlanted(context.Context, string) error
 	Delete(context.Context, string) error
 }
@@ -66,7 +68,20 @@ func (s *seedStore) GetByOwnerID(ctx context.Context, ownerID string) ([]*models
 	q := `SELECT id, owner_id, hp, planted, optimal_soil, botanical_name, created_at FROM seeds
 			WHERE owner_id = $1 AND planted = false;`
 
-	rows, err := s.db.QueryContext(ctx, q, ownerID)
+	return s.getMany(ctx, q, ownerID)
+}
+
+// GetAllByOwnerID returns every seed the user has had, including the ones they have planted.
+func (s *seedStore) GetAllByOwnerID(ctx context.Context, ownerID string) ([]*models.Seed, error) {
+	q := `SELECT id, owner_id, hp, planted, optimal_soil, botanical_name, created_at FROM seeds
+			WHERE owner_id = $1
+			ORDER BY created_at ASC;`
+
+	return s.getMany(ctx, q, ownerID)
+}
+
+func (s *seedStore) getMany(ctx context.Context, q string, args ...any) ([]*models.Seed, error) {
+	rows, err := s.db.QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -204,3 +219,33 @@ func (s *seedStore) GetLastFulfilledSeedRequestTimeByUserID(ctx context.Context,
 
 	return requestedAt, nil
 }
+
+func (s *seedStore) GetSeedRequestsByUserID(ctx context.Context, userID string) ([]*models.SeedRequest, error) {
+	q := `SELECT requested_at, fulfilled, seed_count FROM seed_requests
+			WHERE user_id = $1
+			ORDER BY requested_at ASC;`
+
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	seedRequests := make([]*models.SeedRequest, 0)
+
+	for rows.Next() {
+		seedRequest := new(models.SeedRequest)
+		if err := rows.Scan(&seedRequest.RequestedAt, &seedRequest.Fulfilled, &seedRequest.SeedCount); err != nil {
+			return nil, err
+		}
+
+		seedRequests = append(seedRequests, seedRequest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return seedRequests, nil
+}
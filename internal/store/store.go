@@ -13,6 +13,14 @@ type Store struct {
 	Soil         SoilStore
 	Seed         SeedStore
 	RefreshToken RefreshTokenStore
+	Achievement  AchievementStore
+	PlantEvent   PlantEventStore
+
+	EmailVerificationToken EmailVerificationTokenStore
+	PasswordReset          PasswordResetStore
+	LoginFailures          LoginFailuresStore
+	Identity               IdentityStore
+	Admin                  AdminStore
 }
 
 var (
@@ -36,6 +44,14 @@ func NewStore(db *sql.DB) *Store {
 		Plant:        &plantStore{db},
 		Soil:         &soilStore{db},
 		RefreshToken: &refreshTokenStore{db},
+		Achievement:  &achievementStore{db},
+		PlantEvent:   &plantEventStore{db},
+
+		EmailVerificationToken: &emailVerificationTokenStore{db},
+		PasswordReset:          &passwordResetStore{db},
+		LoginFailures:          &loginFailuresStore{db},
+		Identity:               &identityStore{db},
+		Admin:                  &adminStore{db},
 	}
 }
 
@@ -54,5 +70,13 @@ func (s *Store) WithTx(transaction *Transaction) *Store {
 		Plant:        &plantStore{transaction.tx},
 		Soil:         &soilStore{transaction.tx},
 		RefreshToken: &refreshTokenStore{transaction.tx},
+		Achievement:  &achievementStore{transaction.tx},
+		PlantEvent:   &plantEventStore{transaction.tx},
+
+		EmailVerificationToken: &emailVerificationTokenStore{transaction.tx},
+		PasswordReset:          &passwordResetStore{transaction.tx},
+		LoginFailures:          &loginFailuresStore{transaction.tx},
+		Identity:               &identityStore{transaction.tx},
+		Admin:                  &adminStore{transaction.tx},
 	}
 }
@@ -0,0 +1,10 @@
+INSERT INTO users (id, username, email, password_hash) VALUES
+  ('00000000-0000-4000-a000-000000000001', 'testuser', 'test@example.com', '\x0123456789ABCDEF');
+
+INSERT INTO soils (id, soil_type, water_retention, nutrient_richness, radius_m, centre) VALUES
+  ('00000000-0000-4000-c000-000000000101', 'loam', 0.55, 0.75, 22.0,
+   ST_GeogFromText('POINT(-73.965355 40.782865)'));
+
+INSERT INTO plants (id, nickname, hp, owner_id, centre, fuzzed_centre, radius_m, soil_id, optimal_soil, botanical_name, woe, frolic, dread, malice) VALUES
+  ('00000000-0000-4000-d000-000000000001', 'Fuzzy Sprout', 90.0, '00000000-0000-4000-a000-000000000001',
+   ST_GeogFromText('POINT(-73.965355 40.782865)'), ST_GeogFromText('POINT(-73.965355 40.783265)'), 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3);
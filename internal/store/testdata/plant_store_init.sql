@@ -0,0 +1,30 @@
+INSERT INTO users (id, username, email, password_hash) VALUES
+  ('00000000-0000-4000-a000-000000000001', 'viewer', 'viewer@example.com', '\x0123456789ABCDEF'),
+  ('00000000-0000-4000-a000-000000000002', 'owner', 'owner@example.com', '\x0123456789ABCDEF');
+
+INSERT INTO soils (id, soil_type, water_retention, nutrient_richness, radius_m, centre) VALUES
+  ('00000000-0000-4000-c000-000000000101', 'loam', 0.55, 0.75, 38.9,
+   ST_GeogFromText('POINT(-73.965355 40.782865)')),
+  ('00000000-0000-4000-c000-000000000102', 'clay', 0.80, 0.65, 25.5,
+   ST_GeogFromText('POINT(-73.900000 40.700000)'));
+
+-- shown 44m north of where it is
+INSERT INTO plants (id, nickname, hp, owner_id, centre, fuzzed_centre, radius_m, soil_id, optimal_soil, botanical_name, woe, frolic, dread, malice) VALUES
+  ('00000000-0000-4000-d000-000000000001', 'Hidden Sprout', 90.0, '00000000-0000-4000-a000-000000000002',
+   ST_GeogFromText('POINT(-73.965355 40.782865)'), ST_GeogFromText('POINT(-73.965355 40.783265)'), 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3);
+
+-- shared exactly, 11m south
+INSERT INTO plants (id, nickname, hp, owner_id, centre, fuzzed_centre, share_exact_location, radius_m, soil_id, optimal_soil, botanical_name, woe, frolic, dread, malice) VALUES
+  ('00000000-0000-4000-d000-000000000002', 'Shared Sprout', 90.0, '00000000-0000-4000-a000-000000000002',
+   ST_GeogFromText('POINT(-73.965355 40.782765)'), ST_GeogFromText('POINT(-73.965355 40.790000)'), true, 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3);
+
+-- the viewer's own plant, a dead plant and a plant too far away are never returned
+INSERT INTO plants (id, nickname, hp, owner_id, centre, fuzzed_centre, radius_m, soil_id, optimal_soil, botanical_name, woe, frolic, dread, malice) VALUES
+  ('00000000-0000-4000-d000-000000000003', 'Own Sprout', 90.0, '00000000-0000-4000-a000-000000000001',
+   ST_GeogFromText('POINT(-73.965355 40.782865)'), ST_GeogFromText('POINT(-73.965355 40.782865)'), 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3),
+  ('00000000-0000-4000-d000-000000000005', 'Far Sprout', 90.0, '00000000-0000-4000-a000-000000000002',
+   ST_GeogFromText('POINT(-73.900000 40.700000)'), ST_GeogFromText('POINT(-73.900000 40.700000)'), 15.0, '00000000-0000-4000-c000-000000000102', 'clay', 'Quercus alba', 3, 3, 3, 3);
+
+INSERT INTO plants (id, nickname, hp, dead, time_of_death, owner_id, centre, fuzzed_centre, radius_m, soil_id, optimal_soil, botanical_name, woe, frolic, dread, malice) VALUES
+  ('00000000-0000-4000-d000-000000000004', 'Dead Sprout', 0.0, true, NOW(), '00000000-0000-4000-a000-000000000002',
+   ST_GeogFromText('POINT(-73.965355 40.782865)'), ST_GeogFromText('POINT(-73.965355 40.782865)'), 15.0, '00000000-0000-4000-c000-000000000101', 'loam', 'Quercus alba', 3, 3, 3, 3);
@@ -0,0 +1,2 @@
+INSERT INTO users (id, username, email, password_hash) VALUES
+  ('00000000-0000-4000-a000-000000000001', 'testuser', 'test@example.com', '\x0123456789ABCDEF');
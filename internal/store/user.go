@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/jasonuc/moota/internal/models"
 )
@@ -12,9 +14,22 @@ type UserStore interface {
 	Insert(context.Context, *models.User) error
 	GetByEmail(context.Context, string) (*models.User, error)
 	GetByID(context.Context, string) (*models.User, error)
+	GetByIDForUpdate(context.Context, string) (*models.User, error)
 	GetByUsername(context.Context, string) (*models.User, error)
 	Update(context.Context, *models.User) error
+	UpdateProgress(context.Context, string, models.LevelMeta) error
+	UpdateTitle(context.Context, string, string) error
+	UpdateUsername(context.Context, string, string) error
+	UpdateEmail(context.Context, string, string) error
+	SetEmailVerified(context.Context, string) error
+	UpdatePasswordHash(context.Context, string, []byte) error
 	Delete(context.Context, string) error
+	GetTokenVersion(context.Context, string) (int64, error)
+	IncrementTokenVersion(context.Context, string) (int64, error)
+	SetDeletionScheduledAt(context.Context, string, *time.Time) error
+	DeleteScheduledBefore(context.Context, time.Time, int) (int, error)
+	Search(context.Context, string, int, int) ([]*models.User, error)
+	SetRole(context.Context, string, models.Role) error
 }
 
 type userStore struct {
@@ -24,11 +39,11 @@ type userStore struct {
 func (s *userStore) Insert(ctx context.Context, user *models.User) error {
 	q := `INSERT INTO users (username, email, password_hash, level, xp, title)
    	VALUES ($1, $2, $3, $4, $5, $6)
-   	RETURNING id, created_at, updated_at;`
+   	RETURNING id, role, created_at, updated_at;`
 
 	err := s.db.QueryRowContext(
 		ctx, q, user.Username, nullIfEmpty(user.Email), user.PasswordHash, user.Level, user.XP, user.Title,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -37,15 +52,15 @@ func (s *userStore) Insert(ctx context.Context, user *models.User) error {
 }
 
 func (s *userStore) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	q := `SELECT id, username, email, password_hash, created_at, updated_at, level, xp, title
+	q := `SELECT id, username, email, email_verified, role, password_hash, created_at, updated_at, level, xp, title, token_version, deletion_scheduled_at
    	FROM users WHERE email = $1;`
 
 	user := &models.User{}
 	var emailVal sql.NullString
 
 	err := s.db.QueryRowContext(ctx, q, email).Scan(
-		&user.ID, &user.Username, &emailVal, &user.PasswordHash,
-		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title,
+		&user.ID, &user.Username, &emailVal, &user.EmailVerified, &user.Role, &user.PasswordHash,
+		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title, &user.TokenVersion, &user.DeletionScheduledAt,
 	)
 
 	if err != nil {
@@ -60,15 +75,15 @@ func (s *userStore) GetByEmail(ctx context.Context, email string) (*models.User,
 }
 
 func (s *userStore) GetByID(ctx context.Context, id string) (*models.User, error) {
-	q := `SELECT id, username, email, password_hash, created_at, updated_at, level, xp, title
+	q := `SELECT id, username, email, email_verified, role, password_hash, created_at, updated_at, level, xp, title, token_version, deletion_scheduled_at
    	FROM users WHERE id = $1;`
 
 	user := &models.User{}
 	var emailVal sql.NullString
 
 	err := s.db.QueryRowContext(ctx, q, id).Scan(
-		&user.ID, &user.Username, &emailVal, &user.PasswordHash,
-		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title,
+		&user.ID, &user.Username, &emailVal, &user.EmailVerified, &user.Role, &user.PasswordHash,
+		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title, &user.TokenVersion, &user.DeletionScheduledAt,
 	)
 
 	if err != nil {
@@ -82,16 +97,41 @@ func (s *userStore) GetByID(ctx context.Context, id string) (*models.User, error
 	return user, nil
 }
 
+// GetByIDForUpdate locks the user's row until the transaction ends so changes made from it aren't lost to concurrent ones.
+func (s *userStore) GetByIDForUpdate(ctx context.Context, id string) (*models.User, error) {
+	q := `SELECT id, username, email, email_verified, role, password_hash, created_at, updated_at, level, xp, title, token_version, deletion_scheduled_at
+   	FROM users WHERE id = $1
+   	FOR UPDATE;`
+
+	user := &models.User{}
+	var emailVal sql.NullString
+
+	err := s.db.QueryRowContext(ctx, q, id).Scan(
+		&user.ID, &user.Username, &emailVal, &user.EmailVerified, &user.Role, &user.PasswordHash,
+		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title, &user.TokenVersion, &user.DeletionScheduledAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), ErrInvalidUUIDSyntax) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user.Email = emailVal.String
+	return user, nil
+}
+
 func (s *userStore) GetByUsername(ctx context.Context, username string) (*models.User, error) {
-	q := `SELECT id, username, email, password_hash, created_at, updated_at, level, xp, title
+	q := `SELECT id, username, email, email_verified, role, password_hash, created_at, updated_at, level, xp, title, token_version, deletion_scheduled_at
    	FROM users WHERE username = $1;`
 
 	user := &models.User{}
 	var emailVal sql.NullString
 
 	err := s.db.QueryRowContext(ctx, q, username).Scan(
-		&user.ID, &user.Username, &emailVal, &user.PasswordHash,
-		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title,
+		&user.ID, &user.Username, &emailVal, &user.EmailVerified, &user.Role, &user.PasswordHash,
+		&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title, &user.TokenVersion, &user.DeletionScheduledAt,
 	)
 
 	if err != nil {
@@ -105,18 +145,97 @@ func (s *userStore) GetByUsername(ctx context.Context, username string) (*models
 	return user, nil
 }
 
+// Update saves the user's account details. Progress and title are left alone, they are saved with UpdateProgress and UpdateTitle
+// so account changes can't write back stale values of them.
 func (s *userStore) Update(ctx context.Context, updatedUser *models.User) error {
-	q := `UPDATE users SET username = $1, email = $2, password_hash = $3, level = $4, xp = $5, title = $6, updated_at = NOW()
-   	WHERE id = $7;`
+	q := `UPDATE users SET username = $1, email = $2, password_hash = $3, email_verified = $4, updated_at = NOW()
+   	WHERE id = $5;`
 
 	res, err := s.db.ExecContext(ctx, q,
 		updatedUser.Username, nullIfEmpty(updatedUser.Email), updatedUser.PasswordHash,
-		updatedUser.Level, updatedUser.XP, updatedUser.Title, updatedUser.ID,
+		updatedUser.EmailVerified, updatedUser.ID,
 	)
 	if err != nil {
 		return err
 	}
 
+	return expectUserAffected(res)
+}
+
+func (s *userStore) UpdateProgress(ctx context.Context, id string, progress models.LevelMeta) error {
+	q := `UPDATE users SET level = $2, xp = $3, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, progress.Level, progress.XP)
+	if err != nil {
+		return err
+	}
+
+	return expectUserAffected(res)
+}
+
+func (s *userStore) UpdateTitle(ctx context.Context, id string, title string) error {
+	q := `UPDATE users SET title = $2, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, title)
+	if err != nil {
+		return err
+	}
+
+	return expectUserAffected(res)
+}
+
+func (s *userStore) UpdateUsername(ctx context.Context, id string, username string) error {
+	q := `UPDATE users SET username = $2, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, username)
+	if err != nil {
+		return err
+	}
+
+	return expectUserAffected(res)
+}
+
+// UpdateEmail changes the user's email and marks it unverified.
+func (s *userStore) UpdateEmail(ctx context.Context, id string, email string) error {
+	q := `UPDATE users SET email = $2, email_verified = false, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, nullIfEmpty(email))
+	if err != nil {
+		return err
+	}
+
+	return expectUserAffected(res)
+}
+
+func (s *userStore) SetEmailVerified(ctx context.Context, id string) error {
+	q := `UPDATE users SET email_verified = true, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	return expectUserAffected(res)
+}
+
+func (s *userStore) UpdatePasswordHash(ctx context.Context, id string, passwordHash []byte) error {
+	q := `UPDATE users SET password_hash = $2, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, passwordHash)
+	if err != nil {
+		return err
+	}
+
+	return expectUserAffected(res)
+}
+
+func expectUserAffected(res sql.Result) error {
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
 		return err
@@ -146,3 +265,143 @@ func (s *userStore) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (s *userStore) GetTokenVersion(ctx context.Context, id string) (int64, error) {
+	q := `SELECT token_version FROM users WHERE id = $1;`
+
+	var tokenVersion int64
+	err := s.db.QueryRowContext(ctx, q, id).Scan(&tokenVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrUserNotFound
+		}
+		return 0, err
+	}
+
+	return tokenVersion, nil
+}
+
+// IncrementTokenVersion invalidates every access token issued to the user so far and returns the new version.
+func (s *userStore) IncrementTokenVersion(ctx context.Context, id string) (int64, error) {
+	q := `UPDATE users SET token_version = token_version + 1, updated_at = NOW()
+   	WHERE id = $1
+   	RETURNING token_version;`
+
+	var tokenVersion int64
+	err := s.db.QueryRowContext(ctx, q, id).Scan(&tokenVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrUserNotFound
+		}
+		return 0, err
+	}
+
+	return tokenVersion, nil
+}
+
+// SetDeletionScheduledAt schedules the user to be deleted at the given time, or cancels the deletion when it is nil.
+func (s *userStore) SetDeletionScheduledAt(ctx context.Context, id string, at *time.Time) error {
+	q := `UPDATE users SET deletion_scheduled_at = $2, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, at)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteScheduledBefore deletes up to limit users whose deletion was scheduled before the given time and returns how many it deleted.
+func (s *userStore) DeleteScheduledBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	q := `DELETE FROM users WHERE id IN (
+   		SELECT id FROM users
+   		WHERE deletion_scheduled_at <= $1
+   		ORDER BY deletion_scheduled_at ASC
+   		LIMIT $2
+   		FOR UPDATE SKIP LOCKED
+   	);`
+
+	res, err := s.db.ExecContext(ctx, q, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// Search finds users whose username or email contains the query, or whose ID is the query.
+func (s *userStore) Search(ctx context.Context, query string, limit, offset int) ([]*models.User, error) {
+	q := `SELECT id, username, email, email_verified, role, password_hash, created_at, updated_at, level, xp, title, token_version, deletion_scheduled_at
+   	FROM users
+   	WHERE username ILIKE '%' || $1 || '%' ESCAPE '\' OR email ILIKE '%' || $1 || '%' ESCAPE '\' OR id::text = $2
+   	ORDER BY username ASC
+   	LIMIT $3 OFFSET $4;`
+
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+
+	rows, err := s.db.QueryContext(ctx, q, pattern, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:errcheck
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		user := &models.User{}
+		var emailVal sql.NullString
+
+		if err := rows.Scan(
+			&user.ID, &user.Username, &emailVal, &user.EmailVerified, &user.Role, &user.PasswordHash,
+			&user.CreatedAt, &user.UpdatedAt, &user.Level, &user.XP, &user.Title, &user.TokenVersion, &user.DeletionScheduledAt,
+		); err != nil {
+			return nil, err
+		}
+
+		user.Email = emailVal.String
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *userStore) SetRole(ctx context.Context, id string, role models.Role) error {
+	q := `UPDATE users SET role = $2, updated_at = NOW()
+   	WHERE id = $1;`
+
+	res, err := s.db.ExecContext(ctx, q, id, role)
+	if err != nil {
+		if strings.Contains(err.Error(), ErrInvalidUUIDSyntax) {
+			return models.ErrUserNotFound
+		}
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package utils
 
 import (
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -48,6 +51,15 @@ func UnauthorizedResponse(w http.ResponseWriter) {
 	ErrorResponse(w, http.StatusUnauthorized, message)
 }
 
+// TooManyRequestsResponse tells the client to slow down, and when it can try again if retryAfter is set.
+func TooManyRequestsResponse(w http.ResponseWriter, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	message := "Too many requests, try again later"
+	ErrorResponse(w, http.StatusTooManyRequests, message)
+}
+
 func FailedValidationResponse(w http.ResponseWriter, err error) {
 	errs := make(map[string]string)
 	for _, err := range err.(validator.ValidationErrors) {
@@ -61,3 +73,13 @@ func FailedValidationResponse(w http.ResponseWriter, err error) {
 	}
 	ErrorResponse(w, http.StatusUnprocessableEntity, respEvenlope)
 }
+
+// RejectedFieldResponse reports a single field that was rejected, in the same shape as FailedValidationResponse.
+func RejectedFieldResponse(w http.ResponseWriter, field, reason string) {
+	message := "Request was invalid"
+	respEvenlope := Envelope{
+		"message": message,
+		"fields":  map[string]string{field: reason},
+	}
+	ErrorResponse(w, http.StatusUnprocessableEntity, respEvenlope)
+}
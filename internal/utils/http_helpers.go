@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -43,6 +44,21 @@ func ReadFloatQueryParam(r *http.Request, key string) (float64, error) {
 	return floatVal, nil
 }
 
+// ReadIntQueryParam returns fallback when the query param is missing.
+func ReadIntQueryParam(r *http.Request, key string, fallback int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	intVal, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid query param: %s", key)
+	}
+
+	return intVal, nil
+}
+
 func ReadBoolQueryParam(r *http.Request, key string) bool {
 	value := r.URL.Query().Get(key)
 	if value == "" {
@@ -57,6 +73,15 @@ func ReadBoolQueryParam(r *http.Request, key string) bool {
 	return boolValue
 }
 
+// ReadClientIP returns the IP address of the client without the port.
+func ReadClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 type Envelope map[string]any
 
 func ReadJSON(w http.ResponseWriter, r *http.Request, v any) error {
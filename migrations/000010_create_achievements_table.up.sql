@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS achievements (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    achievement VARCHAR(50) NOT NULL,
+    earned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, achievement)
+);
+
+CREATE TABLE IF NOT EXISTS user_stats (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    waterings INTEGER NOT NULL DEFAULT 0
+);
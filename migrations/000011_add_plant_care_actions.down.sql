@@ -0,0 +1,4 @@
+ALTER TABLE plants
+    DROP COLUMN IF EXISTS last_fertilised_at,
+    DROP COLUMN IF EXISTS last_pruned_at,
+    DROP COLUMN IF EXISTS last_talked_to_at;
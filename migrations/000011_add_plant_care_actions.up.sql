@@ -0,0 +1,4 @@
+ALTER TABLE plants
+    ADD COLUMN last_fertilised_at TIMESTAMPTZ,
+    ADD COLUMN last_pruned_at TIMESTAMPTZ,
+    ADD COLUMN last_talked_to_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_plant_events_plant_id_occurred_at;
+
+DROP TABLE IF EXISTS plant_events;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS plant_events (
+    id BIGSERIAL PRIMARY KEY,
+    plant_id UUID NOT NULL REFERENCES plants (id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    action SMALLINT,
+    actor_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    location GEOGRAPHY (POINT),
+    hp_delta REAL NOT NULL DEFAULT 0,
+    xp_delta INTEGER NOT NULL DEFAULT 0,
+    level SMALLINT,
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_plant_events_plant_id_occurred_at ON plant_events (plant_id, occurred_at DESC, id DESC);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_plant_events_actor_id_occurred_at;
+
+ALTER TABLE user_stats DROP COLUMN IF EXISTS community_waterings;
+
+DROP TABLE IF EXISTS plant_helpers;
+
+ALTER TABLE plants DROP COLUMN IF EXISTS care_permission;
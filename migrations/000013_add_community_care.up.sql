@@ -0,0 +1,11 @@
+ALTER TABLE plants ADD COLUMN care_permission VARCHAR(20) NOT NULL DEFAULT 'owner';
+
+CREATE TABLE IF NOT EXISTS plant_helpers (
+    plant_id UUID NOT NULL REFERENCES plants (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (plant_id, user_id)
+);
+
+ALTER TABLE user_stats ADD COLUMN community_waterings INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_plant_events_actor_id_occurred_at ON plant_events (actor_id, occurred_at);
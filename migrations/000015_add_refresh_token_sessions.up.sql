@@ -0,0 +1,11 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN session_created_at TIMESTAMPTZ,
+    ADD COLUMN user_agent TEXT NOT NULL DEFAULT '',
+    ADD COLUMN ip_address TEXT NOT NULL DEFAULT '';
+
+-- a session starts when the first token in its family was issued
+UPDATE refresh_tokens t SET session_created_at = f.started_at
+FROM (SELECT family_id, MIN(created_at) AS started_at FROM refresh_tokens GROUP BY family_id) f
+WHERE t.family_id = f.family_id;
+
+ALTER TABLE refresh_tokens ALTER COLUMN session_created_at SET NOT NULL;
@@ -0,0 +1,6 @@
+-- failed logins are counted per username and per IP address, keyed as "username:<name>" or "ip:<address>"
+CREATE TABLE IF NOT EXISTS login_failures (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL,
+    last_failed_at TIMESTAMPTZ NOT NULL
+);
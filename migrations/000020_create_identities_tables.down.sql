@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS oidc_signups;
+
+DROP TABLE IF EXISTS oidc_login_states;
+
+DROP TABLE IF EXISTS identities;
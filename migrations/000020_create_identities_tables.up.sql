@@ -0,0 +1,36 @@
+-- links a user to the subject an OIDC provider knows them by
+CREATE TABLE IF NOT EXISTS identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email CITEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    last_login_at TIMESTAMPTZ,
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+-- logins that have been sent to a provider and not come back yet
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    link_user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- provider logins that didn't match a user and are waiting for a username to be picked
+CREATE TABLE IF NOT EXISTS oidc_signups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash BYTEA NOT NULL UNIQUE,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email CITEXT,
+    email_verified BOOLEAN NOT NULL,
+    suggested_username TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,16 @@
+-- there is no endpoint to make the first admin, promote them by hand:
+-- UPDATE users SET role = 'admin' WHERE username = '...';
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    action VARCHAR(40) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log (created_at DESC, id DESC);
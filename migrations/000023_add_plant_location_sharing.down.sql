@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_plants_shown_centre;
+
+ALTER TABLE plants DROP COLUMN IF EXISTS shown_centre;
+
+ALTER TABLE plants DROP COLUMN IF EXISTS fuzzed_centre;
+
+ALTER TABLE plants DROP COLUMN IF EXISTS share_exact_location;
@@ -0,0 +1,14 @@
+-- other players see plants at fuzzed_centre, a random point within 100m of the plant that is picked once so it
+-- can't be averaged out, unless the owner shares the exact location
+ALTER TABLE plants ADD COLUMN share_exact_location BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE plants ADD COLUMN fuzzed_centre GEOGRAPHY (POINT);
+
+UPDATE plants SET fuzzed_centre = ST_Project(centre, 100 * sqrt(random()), 2 * pi() * random());
+
+ALTER TABLE plants ALTER COLUMN fuzzed_centre SET NOT NULL;
+
+ALTER TABLE plants ADD COLUMN shown_centre GEOGRAPHY (POINT)
+    GENERATED ALWAYS AS (CASE WHEN share_exact_location THEN centre ELSE fuzzed_centre END) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_plants_shown_centre ON plants USING GIST (shown_centre) WHERE dead = false;
@@ -0,0 +1 @@
+ALTER TABLE oidc_login_states DROP COLUMN IF EXISTS set_password_user_id;
@@ -0,0 +1,2 @@
+-- set when a user without a password is logging in with a provider again so they can set one
+ALTER TABLE oidc_login_states ADD COLUMN set_password_user_id UUID REFERENCES users(id) ON DELETE CASCADE;